@@ -18,14 +18,27 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/urfave/cli/v2"
 
 	"github.com/LokiWager/analysis-demo/pkg/ast"
+	"github.com/LokiWager/analysis-demo/pkg/config"
+	"github.com/LokiWager/analysis-demo/pkg/lint"
+)
+
+const (
+	// exitClean is the exit code when no finding reaches the fail-on threshold
+	exitClean = 0
+	// exitFindings is the exit code when findings reach the fail-on threshold
+	exitFindings = 1
+	// exitError is the exit code when the analysis itself fails
+	exitError = 2
 )
 
 var versionTag string
@@ -42,60 +55,529 @@ func main() {
 	logrus.Infof("Version: %s\n", version)
 
 	app := &cli.App{
-		Name:    "analysis",
-		Usage:   "A CLI tool to analyze Go code",
-		Version: version,
-		Flags: []cli.Flag{
-			&cli.StringFlag{Name: "path", Value: ".", Usage: "Path to the Go source code"},
+		Name:      "analysis",
+		Usage:     "A CLI tool to analyze Go code",
+		Version:   version,
+		ArgsUsage: "[packages]",
+		Commands: []*cli.Command{
+			complexityCommand(),
+			docCoverageCommand(),
+			grepCommand(),
+			clonesCommand(),
 		},
+		Flags: append(loadFlags(),
+			&cli.StringSliceFlag{Name: "enable", Usage: "Rules to run, the default rules if empty, opt-in rules like magic-literal only by name"},
+			&cli.StringSliceFlag{Name: "disable", Usage: "Rules to skip"},
+			&cli.StringFlag{Name: "format", Value: string(lint.FormatText), Usage: "Output format, one of " + strings.Join(lint.Formats(), ", ")},
+			&cli.StringFlag{Name: "output", Usage: "File to write the report to, stdout if empty"},
+			&cli.StringSliceFlag{Name: "severity", Usage: "Severity overrides as rule=error|warning|info"},
+			&cli.StringSliceFlag{Name: "param", Usage: "Rule parameters as rule.param=value, e.g. nesting-depth.max-depth=3"},
+			&cli.BoolFlag{Name: "report-unused-suppressions", Usage: "Report //lint:ignore directives matching no finding"},
+			&cli.StringFlag{Name: "baseline", Usage: "Baseline file, only findings not in it are reported"},
+			&cli.StringFlag{Name: "write-baseline", Usage: "Write the current findings to the baseline file and exit"},
+			&cli.StringFlag{Name: "diff-base", Usage: "Only report findings on lines changed since the git ref, - reads a unified diff from stdin"},
+			&cli.IntFlag{Name: "jobs", Aliases: []string{"j"}, Usage: "Number of files analyzed in parallel, the number of CPUs if 0"},
+			&cli.StringFlag{Name: "cache-dir", Usage: "Directory of the result cache, the user cache directory if empty"},
+			&cli.BoolFlag{Name: "no-cache", Usage: "Analyze all files without the result cache"},
+			&cli.StringFlag{Name: "fail-on", Value: string(ast.SeverityWarning), Usage: "Lowest severity failing the run, one of error, warning, info, none"},
+		),
 		Action: func(c *cli.Context) error {
-			path := c.String("path")
-			if path == "" {
-				path = "."
+			project := loadProject(c)
+			enable, disable := c.StringSlice("enable"), c.StringSlice("disable")
+
+			severities, err := lint.ParseSeverities(c.StringSlice("severity"))
+			if err != nil {
+				logrus.Warnf("Invalid severity: %v", err)
+				os.Exit(exitError)
 			}
 
-			logrus.Infof("Analyzing Go source code in %s", path)
+			params, err := lint.ParseParams(c.StringSlice("param"))
+			if err != nil {
+				logrus.Warnf("Invalid parameter: %v", err)
+				os.Exit(exitError)
+			}
 
-			// read the go source code
-			if _, err := os.Stat(path); os.IsNotExist(err) {
-				logrus.Warnf("Path %s does not exist", path)
-				os.Exit(1)
+			runConfig := &lint.RunConfig{
+				Enable:                   enable,
+				Disable:                  disable,
+				Severities:               severities,
+				Params:                   params,
+				ReportUnusedSuppressions: c.Bool("report-unused-suppressions"),
+				Workers:                  c.Int("jobs"),
+				Project:                  project,
+			}
+			// validate the rules and their parameters before loading any package
+			if err := runConfig.Validate(); err != nil {
+				logrus.Warnf("Failed to create rules: %v", err)
+				os.Exit(exitError)
 			}
 
-			entries, err := os.ReadDir(path)
+			failOn, err := lint.ParseFailOn(c.String("fail-on"))
 			if err != nil {
-				logrus.Warnf("Failed to read directory %s: %v", path, err)
-				os.Exit(1)
+				logrus.Warnf("Invalid fail-on: %v", err)
+				os.Exit(exitError)
 			}
 
-			for _, entry := range entries {
-				if entry.IsDir() {
-					continue
+			format, err := lint.ParseFormat(formatName(c, project))
+			if err != nil {
+				logrus.Warnf("Invalid format: %v", err)
+				os.Exit(exitError)
+			}
+
+			files := loadFiles(c, project, c.Args().Slice())
+
+			// type-check the packages only if a rule needs it, the rules requiring types are skipped without
+			if requires, _ := runConfig.RequiresTypes(); requires {
+				runConfig.Types, err = lint.LoadTypes(loadConfig(c, c.Args().Slice()))
+				if err != nil {
+					logrus.Warnf("Failed to type-check packages, skipping the rules requiring types: %v", err)
 				}
+			}
 
-				if filepath.Ext(entry.Name()) != ".go" {
-					continue
+			if !c.Bool("no-cache") {
+				runConfig.Cache, err = newCache(c.String("cache-dir"))
+				if err != nil {
+					logrus.Warnf("Failed to open cache, analyzing all files: %v", err)
 				}
+			}
+
+			report, err := lint.Run(files, runConfig)
+			if err != nil {
+				logrus.Warnf("Failed to analyze packages: %v", err)
+				os.Exit(exitError)
+			}
 
-				logrus.Infof("Analyzing %s", entry.Name())
-				e := ast.NewEngine(filepath.Join(path, entry.Name()), nil)
-				ok := e.CheckIdentifiers()
-				if !ok {
-					logrus.Infof("\t Found identifier with length 13")
+			if output := c.String("write-baseline"); output != "" {
+				baseline, err := lint.NewBaseline(report.Findings, baselineRoot(c, project))
+				if err == nil {
+					err = baseline.Write(output)
 				}
+				if err != nil {
+					logrus.Warnf("Failed to write baseline %s: %v", output, err)
+					os.Exit(exitError)
+				}
+				logrus.Infof("Wrote %d findings to baseline %s", baseline.Len(), output)
+				os.Exit(exitClean)
+			}
 
-				controlFlow := e.CheckControlFlow()
-				if !controlFlow {
-					logrus.Infof("\t Found more than 4 level nested control flow")
+			if input := c.String("baseline"); input != "" {
+				baseline, err := lint.ReadBaseline(input, baselineRoot(c, project))
+				if err == nil {
+					report.Findings, err = baseline.Filter(report.Findings)
+				}
+				if err != nil {
+					logrus.Warnf("Failed to apply baseline %s: %v", input, err)
+					os.Exit(exitError)
 				}
 			}
 
+			if base := c.String("diff-base"); base != "" {
+				changed, err := changedLines(c.String("path"), base)
+				if err == nil {
+					report.Findings, err = changed.Filter(report.Findings)
+				}
+				if err != nil {
+					logrus.Warnf("Failed to filter changed lines: %v", err)
+					os.Exit(exitError)
+				}
+			}
+
+			writeReport(c, func(w io.Writer) error {
+				return lint.WriteReport(w, format, report)
+			})
+
+			if report.Failed(failOn) {
+				os.Exit(exitFindings)
+			}
+			os.Exit(exitClean)
 			return nil
 		},
 	}
 
 	err := app.Run(os.Args)
 	if err != nil {
-		logrus.Fatal(err)
+		logrus.Error(err)
+		os.Exit(exitError)
+	}
+}
+
+// loadFlags are the flags selecting the files to analyze
+func loadFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{Name: "path", Value: ".", Usage: "Directory the package patterns are resolved in"},
+		&cli.StringFlag{Name: "config", Usage: "Project configuration file, the first " + config.FileName + " found from --path upwards if empty"},
+		&cli.StringSliceFlag{Name: "tags", Usage: "Build tags to honor"},
+		&cli.StringFlag{Name: "goos", Usage: "Target operating system, the host one if empty"},
+		&cli.StringFlag{Name: "goarch", Usage: "Target architecture, the host one if empty"},
+		&cli.BoolFlag{Name: "tests", Usage: "Include _test.go files"},
+	}
+}
+
+// loadProject reads the project configuration given by --config or found from --path upwards, it exits on errors
+func loadProject(c *cli.Context) *config.Config {
+	path := c.String("path")
+	if path == "" {
+		path = "."
+	}
+
+	// read the go source code
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		logrus.Warnf("Path %s does not exist", path)
+		os.Exit(exitError)
+	}
+
+	var project *config.Config
+	var err error
+	if file := c.String("config"); file != "" {
+		project, err = config.Load(file)
+	} else {
+		project, err = config.Find(path)
+	}
+	if err != nil {
+		logrus.Warnf("Failed to read configuration: %v", err)
+		os.Exit(exitError)
+	}
+	if project.Path != "" {
+		logrus.Infof("Using configuration %s", project.Path)
+	}
+
+	return project
+}
+
+// loadFiles resolves the package patterns into the files to analyze,
+// files excluded by the project configuration are skipped, it exits on errors
+func loadFiles(c *cli.Context, project *config.Config, patterns []string) []string {
+	return includedFiles(loadConfig(c, patterns), project)
+}
+
+// includedFiles resolves the packages of the load configuration into the files to analyze,
+// files excluded by the project configuration are skipped, it exits on errors
+func includedFiles(load *lint.LoadConfig, project *config.Config) []string {
+	logrus.Infof("Analyzing Go packages %v in %s", load.Patterns, load.Dir)
+
+	files, err := lint.LoadFiles(load)
+	if err != nil {
+		logrus.Warnf("Failed to load packages: %v", err)
+		os.Exit(exitError)
+	}
+
+	included := make([]string, 0, len(files))
+	for _, file := range files {
+		if project.Includes(file) {
+			included = append(included, relPath(file))
+		}
+	}
+
+	return included
+}
+
+// loadConfig returns the configuration resolving the package patterns
+func loadConfig(c *cli.Context, patterns []string) *lint.LoadConfig {
+	path := c.String("path")
+	if path == "" {
+		path = "."
+	}
+
+	return &lint.LoadConfig{
+		Dir:      path,
+		Patterns: patterns,
+		Tags:     c.StringSlice("tags"),
+		GOOS:     c.String("goos"),
+		GOARCH:   c.String("goarch"),
+		Tests:    c.Bool("tests"),
+	}
+}
+
+// complexityCommand reports the most complex functions of every package
+func complexityCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "complexity",
+		Usage:     "Report the functions with the highest cyclomatic and cognitive complexity per package",
+		ArgsUsage: "[packages]",
+		Flags: append(reportFlags(),
+			&cli.IntFlag{Name: "top", Value: 10, Usage: "Number of functions listed per package, all if 0"},
+			&cli.StringFlag{Name: "sort-by", Value: string(lint.MetricCyclomatic), Usage: "Metric the functions are ordered by, cyclomatic or cognitive"},
+		),
+		Action: func(c *cli.Context) error {
+			metric, err := lint.ParseMetric(c.String("sort-by"))
+			if err != nil {
+				logrus.Warnf("Invalid sort-by: %v", err)
+				os.Exit(exitError)
+			}
+
+			format := reportFormat(c)
+			files := loadFiles(c, loadProject(c), c.Args().Slice())
+			packages, err := lint.Complexity(files, &lint.ComplexityConfig{
+				Top:    c.Int("top"),
+				SortBy: metric,
+			})
+			if err != nil {
+				logrus.Warnf("Failed to analyze packages: %v", err)
+				os.Exit(exitError)
+			}
+
+			writeReport(c, func(w io.Writer) error {
+				return lint.WriteComplexity(w, format, packages)
+			})
+
+			os.Exit(exitClean)
+			return nil
+		},
+	}
+}
+
+// docCoverageCommand reports the percentage of documented exported declarations of every package,
+// it fails when a package is below the minimum given by --min or by the project configuration
+func docCoverageCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "doc-coverage",
+		Usage:     "Report the percentage of exported declarations with a doc comment per package",
+		ArgsUsage: "[packages]",
+		Flags: append(reportFlags(),
+			&cli.Float64Flag{Name: "min", Usage: "Minimum percentage of documented exported declarations of every package, the docCoverage min of the project configuration if not set"},
+		),
+		Action: func(c *cli.Context) error {
+			format := reportFormat(c)
+			project := loadProject(c)
+			minCoverage := project.DocCoverage.Min
+			if c.IsSet("min") {
+				minCoverage = c.Float64("min")
+				if minCoverage < 0 || minCoverage > 100 {
+					logrus.Warnf("Invalid min: %v is not a percentage between 0 and 100", minCoverage)
+					os.Exit(exitError)
+				}
+			}
+
+			packages, err := lint.DocCoverage(loadFiles(c, project, c.Args().Slice()))
+			if err != nil {
+				logrus.Warnf("Failed to analyze packages: %v", err)
+				os.Exit(exitError)
+			}
+
+			writeReport(c, func(w io.Writer) error {
+				return lint.WriteDocCoverage(w, format, packages, minCoverage)
+			})
+
+			if below := lint.BelowMinCoverage(packages, minCoverage); len(below) > 0 {
+				logrus.Warnf("%d packages have a doc coverage below %.1f%%", len(below), minCoverage)
+				os.Exit(exitFindings)
+			}
+
+			os.Exit(exitClean)
+			return nil
+		},
 	}
 }
+
+// grepCommand searches the packages for the code matching a pattern,
+// it exits like grep: 0 when code matches, 1 when none does
+func grepCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "grep",
+		Usage:     "Search the code matching a pattern in Go syntax with $x metavariables, e.g. '$x.State = $v'",
+		ArgsUsage: "<pattern> [packages]",
+		Flags: append(loadFlags(),
+			&cli.StringSliceFlag{Name: "type", Usage: "Type constraint of a metavariable as name=type, e.g. m=*sync.Map"},
+			&cli.StringFlag{Name: "format", Value: string(lint.FormatText), Usage: "Output format, one of " + strings.Join(lint.Formats(), ", ")},
+			&cli.StringFlag{Name: "output", Usage: "File to write the matches to, stdout if empty"},
+			&cli.IntFlag{Name: "jobs", Aliases: []string{"j"}, Usage: "Number of files searched in parallel, the number of CPUs if 0"},
+		),
+		Action: func(c *cli.Context) error {
+			if c.NArg() == 0 {
+				logrus.Warnf("Missing pattern")
+				os.Exit(exitError)
+			}
+			pattern, patterns := c.Args().First(), c.Args().Tail()
+
+			constraints, err := lint.ParseTypeConstraints(c.StringSlice("type"))
+			if err != nil {
+				logrus.Warnf("Invalid type: %v", err)
+				os.Exit(exitError)
+			}
+
+			format, err := lint.ParseFormat(c.String("format"))
+			if err != nil {
+				logrus.Warnf("Invalid format: %v", err)
+				os.Exit(exitError)
+			}
+
+			report, err := lint.Grep(loadFiles(c, loadProject(c), patterns), &lint.GrepConfig{
+				Pattern: pattern,
+				Types:   constraints,
+				Load:    loadConfig(c, patterns),
+				Workers: c.Int("jobs"),
+			})
+			if err != nil {
+				logrus.Warnf("Failed to search packages: %v", err)
+				os.Exit(exitError)
+			}
+
+			writeReport(c, func(w io.Writer) error {
+				return lint.WriteReport(w, format, report)
+			})
+
+			if len(report.Findings) == 0 {
+				os.Exit(exitFindings)
+			}
+			os.Exit(exitClean)
+			return nil
+		},
+	}
+}
+
+// clonesCommand reports the clones among the packages, it exits with 1 when it finds any
+func clonesCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "clones",
+		Usage:     "Report the type-1 and type-2 clones of the packages with their similarity",
+		ArgsUsage: "[packages]",
+		Flags: append(reportFlags(),
+			&cli.IntFlag{Name: "min-tokens", Usage: fmt.Sprintf("Minimum number of tokens of a clone, the clones minTokens of the project configuration or %d if not set", lint.DefaultMinCloneTokens)},
+		),
+		Action: func(c *cli.Context) error {
+			format := reportFormat(c)
+			project := loadProject(c)
+			minTokens := project.Clones.MinTokens
+			if c.IsSet("min-tokens") {
+				minTokens = c.Int("min-tokens")
+			}
+			if minTokens <= 0 {
+				minTokens = lint.DefaultMinCloneTokens
+			}
+
+			// the files of the other operating systems are compared too, e.g. trace.go and trace_win.go
+			load := loadConfig(c, c.Args().Slice())
+			load.Ignored = true
+			pairs, err := lint.Clones(includedFiles(load, project), &lint.ClonesConfig{MinTokens: minTokens})
+			if err != nil {
+				logrus.Warnf("Failed to analyze packages: %v", err)
+				os.Exit(exitError)
+			}
+
+			writeReport(c, func(w io.Writer) error {
+				return lint.WriteClones(w, format, pairs, minTokens)
+			})
+
+			if len(pairs) > 0 {
+				os.Exit(exitFindings)
+			}
+			os.Exit(exitClean)
+			return nil
+		},
+	}
+}
+
+// reportFlags are the flags of the subcommands writing a report, the load flags and the output flags
+func reportFlags() []cli.Flag {
+	return append(loadFlags(),
+		&cli.StringFlag{Name: "format", Value: string(lint.FormatText), Usage: "Output format, text or json"},
+		&cli.StringFlag{Name: "output", Usage: "File to write the report to, stdout if empty"},
+	)
+}
+
+// reportFormat returns the format given by --format of a subcommand writing a report, text or json,
+// it exits on other formats before any package is loaded
+func reportFormat(c *cli.Context) lint.Format {
+	format, err := lint.ParseSummaryFormat(c.String("format"))
+	if err != nil {
+		logrus.Warnf("Invalid format: %v", err)
+		os.Exit(exitError)
+	}
+
+	return format
+}
+
+// writeReport calls write with the file given by --output, stdout if empty, it exits on errors
+func writeReport(c *cli.Context, write func(w io.Writer) error) {
+	if err := writeOutput(c.String("output"), write); err != nil {
+		logrus.Warnf("Failed to write report: %v", err)
+		os.Exit(exitError)
+	}
+}
+
+// formatName returns the output format given by --format, or else by the project configuration
+func formatName(c *cli.Context, project *config.Config) string {
+	if !c.IsSet("format") && project.Format != "" {
+		return project.Format
+	}
+
+	return c.String("format")
+}
+
+// baselineRoot returns the directory the files of a baseline are relative to,
+// the directory of the project configuration, or else the module root of --path, it exits on errors
+func baselineRoot(c *cli.Context, project *config.Config) string {
+	if project.Path != "" {
+		return project.Dir()
+	}
+
+	root, err := lint.ModuleRoot(c.String("path"))
+	if err != nil {
+		logrus.Warnf("Failed to find the module root: %v", err)
+		os.Exit(exitError)
+	}
+
+	return root
+}
+
+// newCache opens the result cache in dir, the default cache directory if empty
+func newCache(dir string) (*lint.Cache, error) {
+	if dir == "" {
+		var err error
+		dir, err = lint.DefaultCacheDir()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return lint.NewCache(dir)
+}
+
+// changedLines returns the lines changed since the base ref, a base of - reads a unified diff from stdin
+// whose paths are relative to the git work tree, or to dir outside of git
+func changedLines(dir, base string) (lint.ChangedLines, error) {
+	if base != "-" {
+		return lint.GitDiff(dir, base)
+	}
+
+	root, err := lint.GitRoot(dir)
+	if err != nil {
+		root = dir
+	}
+
+	return lint.ParseDiff(os.Stdin, root)
+}
+
+// writeOutput calls write with the output file, stdout if empty
+func writeOutput(output string, write func(w io.Writer) error) error {
+	if output == "" {
+		return write(os.Stdout)
+	}
+
+	out, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+
+	err = write(out)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
+
+// relPath returns the path of the file relative to the working directory, so reports are portable
+func relPath(file string) string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return file
+	}
+
+	rel, err := filepath.Rel(wd, file)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return file
+	}
+
+	return rel
+}
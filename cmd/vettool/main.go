@@ -0,0 +1,49 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"log"
+
+	"golang.org/x/tools/go/analysis/multichecker"
+
+	"github.com/LokiWager/analysis-demo/pkg/ast"
+	"github.com/LokiWager/analysis-demo/pkg/config"
+	"github.com/LokiWager/analysis-demo/pkg/typechecker"
+)
+
+// vettool runs the lint rules and the type checker as analyzers,
+// standalone with package patterns or as go vet -vettool=$(which vettool),
+// the rules take the parameters of the .analysis.yaml found from the working directory upwards
+func main() {
+	project, err := config.Find(".")
+	if err != nil {
+		log.Fatalf("read the project configuration failed: %v", err)
+	}
+
+	params := make(map[string]ast.Params, len(project.Rules.Params))
+	for name, ruleParams := range project.Rules.Params {
+		params[name] = ruleParams
+	}
+
+	analyzers, err := ast.Analyzers(params)
+	if err != nil {
+		log.Fatalf("%s: %v", project.Path, err)
+	}
+	multichecker.Main(append(analyzers, typechecker.CheckerAnalyzer)...)
+}
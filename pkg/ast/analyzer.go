@@ -0,0 +1,98 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ast
+
+import (
+	"go/ast"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// NewAnalyzer wraps the rule created by the factory as an analyzer, configured with the parameters if any,
+// the findings of the rule are reported as diagnostics with the rule name as category
+func NewAnalyzer(factory RuleFactory, params Params) *analysis.Analyzer {
+	rule := factory()
+	return &analysis.Analyzer{
+		// analyzer names must be valid identifiers
+		Name: strings.ReplaceAll(rule.Name(), "-", ""),
+		Doc:  rule.Doc(),
+		Run: func(pass *analysis.Pass) (interface{}, error) {
+			for _, file := range pass.Files {
+				rule := factory()
+				if params != nil {
+					if err := ConfigureRules([]Rule{rule}, map[string]Params{rule.Name(): params}); err != nil {
+						return nil, err
+					}
+				}
+
+				e := NewEngineFromFile(pass.Fset, file)
+				e.SetTypesInfo(pass.TypesInfo)
+				e.SetPackageFiles(pass.Files)
+				for _, finding := range e.Run(rule) {
+					pass.Report(diagnosticOf(pass, file, finding))
+				}
+			}
+			return nil, nil
+		},
+	}
+}
+
+// Analyzers returns the analyzers of the default rules in alphabetical order of the rule names, opt-in rules
+// are left out, configured with the parameters keyed by rule name, the parameters are validated up front
+func Analyzers(params map[string]Params) ([]*analysis.Analyzer, error) {
+	names := DefaultRuleNames()
+	rules := make([]Rule, 0, len(names))
+	for _, name := range names {
+		rules = append(rules, RuleRegistry[name]())
+	}
+	if err := ConfigureRules(rules, params); err != nil {
+		return nil, err
+	}
+
+	analyzers := make([]*analysis.Analyzer, 0, len(names))
+	for _, name := range names {
+		analyzers = append(analyzers, NewAnalyzer(RuleRegistry[name], params[name]))
+	}
+
+	return analyzers, nil
+}
+
+func diagnosticOf(pass *analysis.Pass, file *ast.File, finding Finding) analysis.Diagnostic {
+	tokenFile := pass.Fset.File(file.Pos())
+	fixes := make([]analysis.SuggestedFix, 0, len(finding.Fixes))
+	for _, fix := range finding.Fixes {
+		edits := make([]analysis.TextEdit, 0, len(fix.Edits))
+		for _, edit := range fix.Edits {
+			edits = append(edits, analysis.TextEdit{
+				Pos:     tokenFile.Pos(edit.Offset),
+				End:     tokenFile.Pos(edit.End),
+				NewText: []byte(edit.NewText),
+			})
+		}
+		fixes = append(fixes, analysis.SuggestedFix{Message: fix.Message, TextEdits: edits})
+	}
+
+	return analysis.Diagnostic{
+		Pos:            finding.Pos,
+		End:            finding.EndPos,
+		Category:       finding.Rule,
+		Message:        finding.Message,
+		SuggestedFixes: fixes,
+	}
+}
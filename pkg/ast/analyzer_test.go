@@ -0,0 +1,102 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ast_test
+
+import (
+	goast "go/ast"
+	"testing"
+
+	testAssert "github.com/stretchr/testify/assert"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/analysistest"
+
+	"github.com/LokiWager/analysis-demo/pkg/ast"
+)
+
+type renameRule struct{}
+
+func (r *renameRule) Name() string           { return "rename-old" }
+func (r *renameRule) Doc() string            { return "renames Old to New" }
+func (r *renameRule) Severity() ast.Severity { return ast.SeverityInfo }
+
+func (r *renameRule) Visit(ctx *ast.Context, node goast.Node) {
+	fn, ok := node.(*goast.FuncDecl)
+	if !ok || fn.Name.Name != "Old" {
+		return
+	}
+
+	ctx.ReportWithFixes(fn.Name, "function Old is deprecated", ast.Fix{
+		Message: "rename to New",
+		Edits: []ast.TextEdit{{
+			Offset:  ctx.Offset(fn.Name.Pos()),
+			End:     ctx.Offset(fn.Name.End()),
+			NewText: "New",
+		}},
+	})
+}
+
+func TestAnalyzers(t *testing.T) {
+	assert := testAssert.New(t)
+	analyzers, err := ast.Analyzers(nil)
+	assert.NoError(err)
+	assert.Len(analyzers, len(ast.DefaultRuleNames()))
+	assert.NoError(analysis.Validate(analyzers))
+
+	// opt-in rules are not run by default
+	for _, analyzer := range analyzers {
+		assert.NotEqual("magicliteral", analyzer.Name)
+	}
+
+	// the parameters are validated before any package is analyzed
+	_, err = ast.Analyzers(map[string]ast.Params{ast.NestingRuleName: {"max-depth": 0}})
+	assert.Error(err)
+
+	// every default rule has a test package named after its analyzer
+	testData := analysistest.TestData()
+	for _, analyzer := range analyzers {
+		analysistest.Run(t, testData, analyzer, analyzer.Name)
+	}
+}
+
+func TestNewAnalyzer_SuggestedFixes(t *testing.T) {
+	analyzer := ast.NewAnalyzer(func() ast.Rule {
+		return &renameRule{}
+	}, nil)
+	testAssert.Equal(t, "renameold", analyzer.Name)
+
+	testData := analysistest.TestData()
+	analysistest.RunWithSuggestedFixes(t, testData, analyzer, "fixes")
+}
+
+func TestMagicLiteralRule_SuggestedFixes(t *testing.T) {
+	analyzer := ast.NewAnalyzer(func() ast.Rule {
+		return ast.NewMagicLiteralRule()
+	}, nil)
+
+	testData := analysistest.TestData()
+	analysistest.RunWithSuggestedFixes(t, testData, analyzer, "magicliteral")
+}
+
+func TestLicenseHeaderRule_SuggestedFixes(t *testing.T) {
+	analyzer := ast.NewAnalyzer(func() ast.Rule {
+		return ast.NewLicenseHeaderRule()
+	}, ast.Params{"holder": "LokiWager", "year": "2024"})
+
+	testData := analysistest.TestData()
+	analysistest.RunWithSuggestedFixes(t, testData, analyzer, "licenseheader")
+}
@@ -0,0 +1,334 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ast
+
+import (
+	"encoding/binary"
+	"fmt"
+	"go/ast"
+	"go/scanner"
+	"go/token"
+	"hash"
+	"hash/fnv"
+	"reflect"
+	"sort"
+)
+
+type (
+	// CloneFragment is a declaration, a statement or a sequence of statements of a file,
+	// the unit of the clone detection
+	CloneFragment struct {
+		// Position is the start of the fragment
+		Position token.Position
+
+		// End is the end of the fragment
+		End token.Position
+
+		// Tokens is the number of tokens of the fragment
+		Tokens int
+
+		// Exact is the hash of the code of the fragment, positions and comments excepted,
+		// type-1 clones have the same exact hash
+		Exact uint64
+
+		// Normalized is the hash of the code of the fragment with its identifiers and literals abstracted away,
+		// type-2 clones have the same normalized hash
+		Normalized uint64
+
+		// nodes are the declaration, the statement or the sequence of statements
+		nodes []ast.Node
+	}
+
+	// cloneHash are the hashes of a node
+	cloneHash struct {
+		exact, normalized uint64
+	}
+
+	// cloneHasher hashes the nodes of a file bottom-up
+	cloneHasher struct {
+		// hashes are the hashes of the declarations and statements of the file
+		hashes map[ast.Node]cloneHash
+	}
+
+	// prefixHashes are the rolling hashes of the prefixes of a list of statements,
+	// the hash of any sequence of the list is derived from two prefixes in constant time
+	prefixHashes struct {
+		exact, normalized []uint64
+
+		// powers are the powers of cloneBase
+		powers []uint64
+	}
+)
+
+const (
+	// cloneBase is the base of the polynomial rolling hashes of the statement sequences, the 64-bit FNV prime
+	cloneBase = 1099511628211
+
+	// maxCloneSequence is the maximum number of statements of a sequence fragment,
+	// a longer duplicated sequence is reported as consecutive clones
+	maxCloneSequence = 20
+)
+
+// CloneFragments returns the fragments of the file of at least minTokens tokens in source order:
+// its declarations, its statements and the sequences of two to maxCloneSequence consecutive statements of a block
+// src is the source code of the file, used to count the tokens
+func CloneFragments(fileSet *token.FileSet, file *ast.File, src []byte, minTokens int) []CloneFragment {
+	h := &cloneHasher{hashes: make(map[ast.Node]cloneHash)}
+	h.hashValue(reflect.ValueOf(file))
+
+	offsets := tokenOffsets(fileSet, file, src)
+	tokens := func(pos, end token.Pos) int {
+		start, stop := fileSet.Position(pos).Offset, fileSet.Position(end).Offset
+		return sort.SearchInts(offsets, stop) - sort.SearchInts(offsets, start)
+	}
+
+	var fragments []CloneFragment
+	add := func(nodes []ast.Node, hash cloneHash) {
+		pos, end := nodes[0].Pos(), nodes[len(nodes)-1].End()
+		if n := tokens(pos, end); n >= minTokens {
+			fragments = append(fragments, CloneFragment{
+				Position:   fileSet.Position(pos),
+				End:        fileSet.Position(end),
+				Tokens:     n,
+				Exact:      hash.exact,
+				Normalized: hash.normalized,
+				nodes:      nodes,
+			})
+		}
+	}
+
+	ast.Inspect(file, func(node ast.Node) bool {
+		if hash, exists := h.hashes[node]; exists {
+			add([]ast.Node{node}, hash)
+		}
+
+		stmts := stmtList(node)
+		if len(stmts) < 2 {
+			return true
+		}
+
+		// every sequence is a slice of the nodes and its hashes are derived from the prefix hashes,
+		// so a block of n statements costs O(n) and not O(n³)
+		nodes := make([]ast.Node, len(stmts))
+		for i, stmt := range stmts {
+			nodes[i] = stmt
+		}
+		prefixes := h.prefixHashes(stmts)
+		for i := range stmts {
+			for j := i + 2; j <= min(len(stmts), i+maxCloneSequence); j++ {
+				add(nodes[i:j], prefixes.sequence(i, j))
+			}
+		}
+		return true
+	})
+
+	sort.SliceStable(fragments, func(i, j int) bool {
+		return fragments[i].Position.Offset < fragments[j].Position.Offset
+	})
+
+	return fragments
+}
+
+// Contains reports whether the fragment contains the other one, a fragment contains itself
+func (f CloneFragment) Contains(other CloneFragment) bool {
+	return f.Position.Filename == other.Position.Filename &&
+		f.Position.Offset <= other.Position.Offset && other.End.Offset <= f.End.Offset
+}
+
+// Overlaps reports whether the fragments share code
+func (f CloneFragment) Overlaps(other CloneFragment) bool {
+	return f.Position.Filename == other.Position.Filename &&
+		f.Position.Offset < other.End.Offset && other.Position.Offset < f.End.Offset
+}
+
+// Similarity returns the share of the identifiers and literals of the fragments that are equal, in order,
+// 1 for type-1 clones
+func (f CloneFragment) Similarity(other CloneFragment) float64 {
+	a, b := f.leaves(), other.leaves()
+	total := max(len(a), len(b))
+	if total == 0 {
+		return 1
+	}
+
+	equal := 0
+	for i := 0; i < min(len(a), len(b)); i++ {
+		if a[i] == b[i] {
+			equal++
+		}
+	}
+
+	return float64(equal) / float64(total)
+}
+
+// leaves returns the identifiers and literals of the fragment in source order
+func (f CloneFragment) leaves() []string {
+	var leaves []string
+	for _, node := range f.nodes {
+		ast.Inspect(node, func(node ast.Node) bool {
+			switch x := node.(type) {
+			case *ast.Ident:
+				leaves = append(leaves, x.Name)
+			case *ast.BasicLit:
+				leaves = append(leaves, x.Value)
+			}
+			return true
+		})
+	}
+
+	return leaves
+}
+
+// hashValue hashes the value of the syntax tree, the hashes of the declarations and statements are recorded
+func (h *cloneHasher) hashValue(v reflect.Value) cloneHash {
+	exact, normalized := fnv.New64a(), fnv.New64a()
+	write := func(text string) {
+		exact.Write([]byte(text))
+		normalized.Write([]byte(text))
+	}
+	writeHash := func(hash cloneHash) {
+		writeUint64(exact, hash.exact)
+		writeUint64(normalized, hash.normalized)
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			write("nil")
+			break
+		}
+		// identifiers and literals are abstracted away from the normalized hash
+		switch x := v.Interface().(type) {
+		case *ast.Ident:
+			return cloneHash{exact: hashString("ident " + x.Name), normalized: hashString("ident")}
+		case *ast.BasicLit:
+			return cloneHash{exact: hashString(x.Kind.String() + " " + x.Value), normalized: hashString(x.Kind.String())}
+		}
+
+		hash := h.hashValue(v.Elem())
+		if node, ok := v.Interface().(ast.Node); ok && isCloneUnit(node) {
+			h.hashes[node] = hash
+		}
+		return hash
+	case reflect.Struct:
+		write(v.Type().Name())
+		for i := 0; i < v.NumField(); i++ {
+			if !ignoredFieldTypes[v.Type().Field(i).Type] {
+				writeHash(h.hashValue(v.Field(i)))
+			}
+		}
+	case reflect.Slice:
+		writeUint64(exact, uint64(v.Len()))
+		writeUint64(normalized, uint64(v.Len()))
+		for i := 0; i < v.Len(); i++ {
+			writeHash(h.hashValue(v.Index(i)))
+		}
+	default:
+		write(v.Type().Name() + " " + fmt.Sprint(v.Interface()))
+	}
+
+	return cloneHash{exact: exact.Sum64(), normalized: normalized.Sum64()}
+}
+
+// isCloneUnit reports whether the node is a declaration or a statement, the imports of files are not clones
+func isCloneUnit(node ast.Node) bool {
+	switch x := node.(type) {
+	case *ast.GenDecl:
+		return x.Tok != token.IMPORT
+	case ast.Stmt, ast.Decl:
+		return true
+	}
+
+	return false
+}
+
+// prefixHashes returns the prefix hashes of statements already hashed
+func (h *cloneHasher) prefixHashes(stmts []ast.Stmt) prefixHashes {
+	p := prefixHashes{
+		exact:      make([]uint64, len(stmts)+1),
+		normalized: make([]uint64, len(stmts)+1),
+		powers:     make([]uint64, len(stmts)+1),
+	}
+	p.powers[0] = 1
+	for i, stmt := range stmts {
+		hash := h.hashes[stmt]
+		p.exact[i+1] = p.exact[i]*cloneBase + hash.exact
+		p.normalized[i+1] = p.normalized[i]*cloneBase + hash.normalized
+		p.powers[i+1] = p.powers[i] * cloneBase
+	}
+
+	return p
+}
+
+// sequence returns the hashes of the statements from i to j excluded
+func (p prefixHashes) sequence(i, j int) cloneHash {
+	n := j - i
+	return cloneHash{
+		exact:      sequenceHash(p.exact[j]-p.exact[i]*p.powers[n], n),
+		normalized: sequenceHash(p.normalized[j]-p.normalized[i]*p.powers[n], n),
+	}
+}
+
+// sequenceHash returns the hash of a sequence of n statements from its rolling hash,
+// so sequences do not collide with single nodes
+func sequenceHash(rolling uint64, n int) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte("sequence"))
+	writeUint64(h, uint64(n))
+	writeUint64(h, rolling)
+	return h.Sum64()
+}
+
+// tokenOffsets returns the offsets of the tokens of the file in order, comments and automatic semicolons excepted
+func tokenOffsets(fileSet *token.FileSet, file *ast.File, src []byte) []int {
+	tokenFile := fileSet.File(file.Pos())
+	if tokenFile == nil || tokenFile.Size() != len(src) {
+		return nil
+	}
+
+	// the file is scanned in its own file set, so the file set of the file does not grow
+	scanned := token.NewFileSet().AddFile(tokenFile.Name(), -1, len(src))
+	var s scanner.Scanner
+	s.Init(scanned, src, nil, 0)
+	var offsets []int
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok == token.SEMICOLON && lit == "\n" {
+			continue
+		}
+		offsets = append(offsets, scanned.Offset(pos))
+	}
+
+	return offsets
+}
+
+// hashString returns the hash of the text
+func hashString(text string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(text))
+	return h.Sum64()
+}
+
+// writeUint64 writes the value to the hash
+func writeUint64(h hash.Hash64, value uint64) {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], value)
+	h.Write(buf[:])
+}
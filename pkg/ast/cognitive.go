@@ -0,0 +1,74 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ast
+
+import (
+	"go/ast"
+)
+
+type (
+	// CognitiveRule reports functions whose cognitive complexity is more than max (default 15)
+	CognitiveRule struct {
+		// maxComplexity is the maximum allowed complexity of a function
+		maxComplexity int
+	}
+)
+
+const (
+	// CognitiveRuleName is the name of the CognitiveRule
+	CognitiveRuleName = "cognitive-complexity"
+
+	// defaultMaxCognitive is the default maximum allowed cognitive complexity
+	defaultMaxCognitive = 15
+)
+
+// NewCognitiveRule creates a new CognitiveRule instance with the default limit of 15
+func NewCognitiveRule() *CognitiveRule {
+	return &CognitiveRule{maxComplexity: defaultMaxCognitive}
+}
+
+// Name returns the name of the rule
+func (r *CognitiveRule) Name() string {
+	return CognitiveRuleName
+}
+
+// Doc returns the documentation of the rule
+func (r *CognitiveRule) Doc() string {
+	return "reports functions whose cognitive complexity is more than max (default 15)"
+}
+
+// Severity returns the default severity of the rule
+func (r *CognitiveRule) Severity() Severity {
+	return SeverityWarning
+}
+
+// Configure sets the max parameter
+func (r *CognitiveRule) Configure(params Params) error {
+	return params.Limits(1, map[string]*int{"max": &r.maxComplexity})
+}
+
+// Visit computes the complexity of every function declaration
+func (r *CognitiveRule) Visit(ctx *Context, node ast.Node) {
+	reportComplexity(ctx, node, "cognitive", CognitiveComplexity, r.maxComplexity)
+}
+
+func init() {
+	RegisterRule(CognitiveRuleName, func() Rule {
+		return NewCognitiveRule()
+	})
+}
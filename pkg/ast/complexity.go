@@ -0,0 +1,308 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ast
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+type (
+	// FunctionComplexity is the complexity of a function declaration,
+	// function literals count towards the function declaring them
+	FunctionComplexity struct {
+		// Name is the name of the function, Type.Method for methods
+		Name string
+
+		// Package is the name of the package declaring the function
+		Package string
+
+		// Position is the position of the function declaration
+		Position token.Position
+
+		// Cyclomatic is the McCabe cyclomatic complexity
+		Cyclomatic int
+
+		// Cognitive is the SonarSource cognitive complexity
+		Cognitive int
+	}
+
+	// cognitiveVisitor computes the cognitive complexity of a function body
+	cognitiveVisitor struct {
+		// name is the name of the function, calls to it are recursion
+		name string
+
+		// receiver is the receiver name of the method, empty for functions
+		receiver string
+
+		// nesting is the nesting level of the current node
+		nesting int
+
+		// complexity is the complexity counted so far
+		complexity int
+
+		// elseIfs are the if statements in else branches, they do not add nesting
+		elseIfs map[*ast.IfStmt]bool
+
+		// counted are the logical expressions already counted as part of a sequence
+		counted map[*ast.BinaryExpr]bool
+	}
+)
+
+// Complexities returns the complexity of every function declaration of the file in source order
+func (e *Engine) Complexities() []FunctionComplexity {
+	var complexities []FunctionComplexity
+	for _, decl := range e.file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil {
+			continue
+		}
+
+		complexities = append(complexities, FunctionComplexity{
+			Name:       FuncName(fn),
+			Package:    e.file.Name.Name,
+			Position:   e.fileSet.Position(fn.Pos()),
+			Cyclomatic: CyclomaticComplexity(fn),
+			Cognitive:  CognitiveComplexity(fn),
+		})
+	}
+
+	return complexities
+}
+
+// FuncName returns the name of the function, Type.Method for methods
+func FuncName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return fn.Name.Name
+	}
+
+	return receiverTypeName(fn.Recv.List[0].Type) + "." + fn.Name.Name
+}
+
+// receiverTypeName returns the name of the receiver type without pointer and type parameters
+func receiverTypeName(expr ast.Expr) string {
+	switch x := expr.(type) {
+	case *ast.StarExpr:
+		return receiverTypeName(x.X)
+	case *ast.ParenExpr:
+		return receiverTypeName(x.X)
+	case *ast.IndexExpr:
+		return receiverTypeName(x.X)
+	case *ast.IndexListExpr:
+		return receiverTypeName(x.X)
+	case *ast.Ident:
+		return x.Name
+	}
+
+	return "?"
+}
+
+// CyclomaticComplexity returns the McCabe cyclomatic complexity of the function:
+// 1 plus one for every if, for, range, non-default case and comm clause, && and ||
+func CyclomaticComplexity(fn *ast.FuncDecl) int {
+	complexity := 1
+	ast.Inspect(fn.Body, func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.IfStmt, *ast.ForStmt, *ast.RangeStmt:
+			complexity++
+		case *ast.CaseClause:
+			if n.List != nil {
+				complexity++
+			}
+		case *ast.CommClause:
+			if n.Comm != nil {
+				complexity++
+			}
+		case *ast.BinaryExpr:
+			if n.Op == token.LAND || n.Op == token.LOR {
+				complexity++
+			}
+		}
+		return true
+	})
+
+	return complexity
+}
+
+// CognitiveComplexity returns the SonarSource cognitive complexity of the function:
+//   - if, switch, select, for and range add one plus their nesting level
+//   - else and else if add one without nesting
+//   - every sequence of the same logical operator adds one
+//   - labeled break and continue, goto and recursive calls add one
+//
+// the bodies of control flow statements and function literals are nested one level deeper
+func CognitiveComplexity(fn *ast.FuncDecl) int {
+	v := &cognitiveVisitor{
+		name:    fn.Name.Name,
+		elseIfs: make(map[*ast.IfStmt]bool),
+		counted: make(map[*ast.BinaryExpr]bool),
+	}
+	if fn.Recv != nil && len(fn.Recv.List) > 0 && len(fn.Recv.List[0].Names) > 0 {
+		v.receiver = fn.Recv.List[0].Names[0].Name
+	}
+
+	ast.Walk(v, fn.Body)
+	return v.complexity
+}
+
+// Visit counts the increments of the node, it walks the children of nesting statements itself
+func (v *cognitiveVisitor) Visit(node ast.Node) ast.Visitor {
+	switch n := node.(type) {
+	case *ast.IfStmt:
+		v.visitIf(n)
+		return nil
+	case *ast.SwitchStmt:
+		v.complexity += 1 + v.nesting
+		v.walk(n.Init)
+		v.walk(n.Tag)
+		v.walkNested(n.Body)
+		return nil
+	case *ast.TypeSwitchStmt:
+		v.complexity += 1 + v.nesting
+		v.walk(n.Init)
+		v.walk(n.Assign)
+		v.walkNested(n.Body)
+		return nil
+	case *ast.SelectStmt:
+		v.complexity += 1 + v.nesting
+		v.walkNested(n.Body)
+		return nil
+	case *ast.ForStmt:
+		v.complexity += 1 + v.nesting
+		v.walk(n.Init)
+		v.walk(n.Cond)
+		v.walk(n.Post)
+		v.walkNested(n.Body)
+		return nil
+	case *ast.RangeStmt:
+		v.complexity += 1 + v.nesting
+		v.walk(n.X)
+		v.walkNested(n.Body)
+		return nil
+	case *ast.FuncLit:
+		v.walkNested(n.Body)
+		return nil
+	case *ast.BranchStmt:
+		if n.Tok == token.GOTO || n.Label != nil && (n.Tok == token.BREAK || n.Tok == token.CONTINUE) {
+			v.complexity++
+		}
+	case *ast.BinaryExpr:
+		if (n.Op == token.LAND || n.Op == token.LOR) && !v.counted[n] {
+			v.complexity += v.logicalSequences(n)
+		}
+	case *ast.CallExpr:
+		if v.isRecursive(n) {
+			v.complexity++
+		}
+	}
+
+	return v
+}
+
+func (v *cognitiveVisitor) visitIf(n *ast.IfStmt) {
+	if v.elseIfs[n] {
+		v.complexity++
+	} else {
+		v.complexity += 1 + v.nesting
+	}
+
+	v.walk(n.Init)
+	v.walk(n.Cond)
+	v.walkNested(n.Body)
+
+	switch elseNode := n.Else.(type) {
+	case *ast.IfStmt:
+		v.elseIfs[elseNode] = true
+		v.visitIf(elseNode)
+	case *ast.BlockStmt:
+		v.complexity++
+		v.walkNested(elseNode)
+	}
+}
+
+// logicalSequences returns the number of sequences of the same logical operator in the expression,
+// e.g. 1 for a && b && c and 2 for a && b || c, and marks the nested logical expressions as counted
+func (v *cognitiveVisitor) logicalSequences(expr *ast.BinaryExpr) int {
+	var ops []token.Token
+	var flatten func(expr ast.Expr)
+	flatten = func(expr ast.Expr) {
+		switch x := expr.(type) {
+		case *ast.ParenExpr:
+			flatten(x.X)
+		case *ast.BinaryExpr:
+			if x.Op != token.LAND && x.Op != token.LOR {
+				return
+			}
+			v.counted[x] = true
+			flatten(x.X)
+			ops = append(ops, x.Op)
+			flatten(x.Y)
+		}
+	}
+	flatten(expr)
+
+	sequences := 0
+	for i, op := range ops {
+		if i == 0 || ops[i-1] != op {
+			sequences++
+		}
+	}
+
+	return sequences
+}
+
+// isRecursive reports whether the call is a call of the function itself
+func (v *cognitiveVisitor) isRecursive(call *ast.CallExpr) bool {
+	switch fun := call.Fun.(type) {
+	case *ast.Ident:
+		return v.receiver == "" && fun.Name == v.name
+	case *ast.SelectorExpr:
+		x, ok := fun.X.(*ast.Ident)
+		return ok && v.receiver != "" && x.Name == v.receiver && fun.Sel.Name == v.name
+	}
+
+	return false
+}
+
+func (v *cognitiveVisitor) walk(node ast.Node) {
+	// absent optional parts, e.g. the init statement of an if, are nil
+	if block, ok := node.(*ast.BlockStmt); node == nil || ok && block == nil {
+		return
+	}
+
+	ast.Walk(v, node)
+}
+
+func (v *cognitiveVisitor) walkNested(node ast.Node) {
+	v.nesting++
+	v.walk(node)
+	v.nesting--
+}
+
+// reportComplexity reports the function declaration of the node if its complexity is more than maxComplexity,
+// metric is the name of the complexity
+func reportComplexity(ctx *Context, node ast.Node, metric string, complexity func(*ast.FuncDecl) int, maxComplexity int) {
+	fn, ok := node.(*ast.FuncDecl)
+	if !ok || fn.Body == nil {
+		return
+	}
+
+	if c := complexity(fn); c > maxComplexity {
+		ctx.Reportf(fn.Name, "function %s has %s complexity %d, more than %d", FuncName(fn), metric, c, maxComplexity)
+	}
+}
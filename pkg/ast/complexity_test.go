@@ -0,0 +1,119 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ast_test
+
+import (
+	"testing"
+
+	testAssert "github.com/stretchr/testify/assert"
+
+	"github.com/LokiWager/analysis-demo/pkg/ast"
+)
+
+// TestEngine_Complexities tests the cyclomatic and cognitive complexity of functions
+func TestEngine_Complexities(t *testing.T) {
+	t.Run("Complexities of the SonarSource examples", func(t *testing.T) {
+		assert := testAssert.New(t)
+		src := `
+package main
+
+func sumOfPrimes(max int) int {
+	total := 0
+OUT:
+	for i := 1; i <= max; i++ {
+		for j := 2; j < i; j++ {
+			if i%j == 0 {
+				continue OUT
+			}
+		}
+		total += i
+	}
+	return total
+}
+
+func getWords(number int) string {
+	switch number {
+	case 1:
+		return "one"
+	case 2:
+		return "a couple"
+	case 3:
+		return "a few"
+	default:
+		return "lots"
+	}
+}
+`
+		e, err := ast.NewEngine("sonar.go", src)
+		assert.NoError(err)
+
+		complexities := e.Complexities()
+		if assert.Len(complexities, 2) {
+			assert.Equal("sumOfPrimes", complexities[0].Name)
+			assert.Equal("main", complexities[0].Package)
+			assert.Equal(4, complexities[0].Position.Line)
+			assert.Equal(4, complexities[0].Cyclomatic)
+			assert.Equal(7, complexities[0].Cognitive)
+
+			assert.Equal("getWords", complexities[1].Name)
+			assert.Equal(4, complexities[1].Cyclomatic)
+			assert.Equal(1, complexities[1].Cognitive)
+		}
+	})
+
+	t.Run("Complexities of logical operators, else branches, closures and recursion", func(t *testing.T) {
+		assert := testAssert.New(t)
+		src := `
+package main
+
+type tree struct {
+	left, right *tree
+}
+
+func (t *tree) depth(a, b, c bool) int {
+	if t == nil {
+		return 0
+	} else if a && b && c {
+		return 1
+	} else if a && b || c {
+		return 2
+	} else {
+		walk := func() {
+			if a {
+				return
+			}
+		}
+		walk()
+	}
+	return t.left.depth(a, b, c) + t.depth(a, b, c)
+}
+`
+		e, err := ast.NewEngine("tree.go", src)
+		assert.NoError(err)
+
+		complexities := e.Complexities()
+		if assert.Len(complexities, 1) {
+			assert.Equal("tree.depth", complexities[0].Name)
+			// 1, if, else if, else if, if in the closure, 4 logical operators
+			assert.Equal(9, complexities[0].Cyclomatic)
+			// if 1, else if 1 + 1 sequence, else if 1 + 2 sequences, else 1, closure if 1 + 2 nesting,
+			// recursive call on the receiver 1
+			assert.Equal(11, complexities[0].Cognitive)
+		}
+	})
+}
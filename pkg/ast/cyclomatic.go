@@ -0,0 +1,74 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ast
+
+import (
+	"go/ast"
+)
+
+type (
+	// CyclomaticRule reports functions whose McCabe cyclomatic complexity is more than max (default 10)
+	CyclomaticRule struct {
+		// maxComplexity is the maximum allowed complexity of a function
+		maxComplexity int
+	}
+)
+
+const (
+	// CyclomaticRuleName is the name of the CyclomaticRule
+	CyclomaticRuleName = "cyclomatic-complexity"
+
+	// defaultMaxCyclomatic is the default maximum allowed cyclomatic complexity
+	defaultMaxCyclomatic = 10
+)
+
+// NewCyclomaticRule creates a new CyclomaticRule instance with the default limit of 10
+func NewCyclomaticRule() *CyclomaticRule {
+	return &CyclomaticRule{maxComplexity: defaultMaxCyclomatic}
+}
+
+// Name returns the name of the rule
+func (r *CyclomaticRule) Name() string {
+	return CyclomaticRuleName
+}
+
+// Doc returns the documentation of the rule
+func (r *CyclomaticRule) Doc() string {
+	return "reports functions whose McCabe cyclomatic complexity is more than max (default 10)"
+}
+
+// Severity returns the default severity of the rule
+func (r *CyclomaticRule) Severity() Severity {
+	return SeverityWarning
+}
+
+// Configure sets the max parameter
+func (r *CyclomaticRule) Configure(params Params) error {
+	return params.Limits(1, map[string]*int{"max": &r.maxComplexity})
+}
+
+// Visit computes the complexity of every function declaration
+func (r *CyclomaticRule) Visit(ctx *Context, node ast.Node) {
+	reportComplexity(ctx, node, "cyclomatic", CyclomaticComplexity, r.maxComplexity)
+}
+
+func init() {
+	RegisterRule(CyclomaticRuleName, func() Rule {
+		return NewCyclomaticRule()
+	})
+}
@@ -0,0 +1,239 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ast
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+type (
+	// DocCommentRule reports exported declarations without a doc comment or whose doc comment
+	// does not start with the declared name, methods of unexported types are not part of the API
+	DocCommentRule struct {
+		// ignoreTests skips the _test.go files
+		ignoreTests bool
+	}
+
+	// ExportedDecl is an exported declaration of a file and whether it has a doc comment
+	ExportedDecl struct {
+		// Name is the name of the declaration, Type.Method for methods
+		Name string
+
+		// Kind is the kind of the declaration, one of func, method, type, const and var
+		Kind string
+
+		// Package is the name of the package of the declaration
+		Package string
+
+		// Position is the position of the declared name
+		Position token.Position
+
+		// Documented reports whether the declaration has a doc comment
+		Documented bool
+	}
+
+	// exportedDecl is an exported declaration and its doc comment
+	exportedDecl struct {
+		// name is the declared name
+		name *ast.Ident
+
+		// kind is the kind of the declaration
+		kind string
+
+		// fullName is the name of the declaration, Type.Method for methods
+		fullName string
+
+		// doc is the doc comment, nil if the declaration has none
+		doc *ast.CommentGroup
+
+		// group reports whether the doc comment documents a group of declarations, it does not start with a name
+		group bool
+	}
+)
+
+const (
+	// DocCommentRuleName is the name of the DocCommentRule
+	DocCommentRuleName = "doc-comment"
+
+	// declKindMethod is the kind of the methods, the other kinds are the name kinds of the naming rule
+	declKindMethod = "method"
+)
+
+// articles may precede the name of a type in its doc comment
+var articles = map[string]bool{"A": true, "An": true, "The": true}
+
+// NewDocCommentRule creates a new DocCommentRule instance ignoring test files
+func NewDocCommentRule() *DocCommentRule {
+	return &DocCommentRule{ignoreTests: true}
+}
+
+// Name returns the name of the rule
+func (r *DocCommentRule) Name() string {
+	return DocCommentRuleName
+}
+
+// Doc returns the documentation of the rule
+func (r *DocCommentRule) Doc() string {
+	return "reports exported declarations without a doc comment starting with their name"
+}
+
+// Severity returns the default severity of the rule
+func (r *DocCommentRule) Severity() Severity {
+	return SeverityInfo
+}
+
+// Configure sets the ignore-tests parameter
+func (r *DocCommentRule) Configure(params Params) error {
+	if err := params.Check("ignore-tests"); err != nil {
+		return err
+	}
+
+	var err error
+	r.ignoreTests, err = params.Bool("ignore-tests", r.ignoreTests)
+	return err
+}
+
+// Visit checks the doc comments of the exported declarations of the file
+func (r *DocCommentRule) Visit(ctx *Context, node ast.Node) {
+	file, ok := node.(*ast.File)
+	if !ok || (r.ignoreTests && isTestFile(ctx)) {
+		return
+	}
+
+	for _, decl := range exportedDecls(file) {
+		switch {
+		case decl.doc == nil:
+			ctx.Reportf(decl.name, "exported %s %s should have a doc comment", kindLabel(decl.kind), decl.fullName)
+		case !decl.group && !startsWithName(decl.doc, decl.name.Name, decl.kind):
+			ctx.Reportf(decl.name, "doc comment of %s %s should start with %s", kindLabel(decl.kind), decl.fullName, decl.name.Name)
+		}
+	}
+}
+
+// ExportedDecls returns the exported declarations of the file in source order
+func (e *Engine) ExportedDecls() []ExportedDecl {
+	var decls []ExportedDecl
+	for _, decl := range exportedDecls(e.file) {
+		decls = append(decls, ExportedDecl{
+			Name:       decl.fullName,
+			Kind:       decl.kind,
+			Package:    e.file.Name.Name,
+			Position:   e.fileSet.Position(decl.name.Pos()),
+			Documented: decl.doc != nil,
+		})
+	}
+
+	return decls
+}
+
+// exportedDecls returns the exported top-level declarations of the file and their doc comments,
+// the doc comment of an unparenthesized declaration documents its only spec
+// and the doc comment of a group of constants or variables documents all of them
+func exportedDecls(file *ast.File) []exportedDecl {
+	var decls []exportedDecl
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if !d.Name.IsExported() {
+				continue
+			}
+			kind := NameKindFunc
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				if !ast.IsExported(receiverTypeName(d.Recv.List[0].Type)) {
+					continue
+				}
+				kind = declKindMethod
+			}
+			decls = append(decls, exportedDecl{name: d.Name, kind: kind, fullName: FuncName(d), doc: d.Doc})
+		case *ast.GenDecl:
+			decls = append(decls, exportedSpecs(d)...)
+		}
+	}
+
+	return decls
+}
+
+// exportedSpecs returns the exported names of the specs of the declaration
+func exportedSpecs(decl *ast.GenDecl) []exportedDecl {
+	var decls []exportedDecl
+	for _, spec := range decl.Specs {
+		doc, group := specDoc(decl, spec)
+		switch s := spec.(type) {
+		case *ast.TypeSpec:
+			if s.Name.IsExported() {
+				decls = append(decls, exportedDecl{name: s.Name, kind: NameKindType, fullName: s.Name.Name, doc: doc, group: group})
+			}
+		case *ast.ValueSpec:
+			kind := NameKindVar
+			if decl.Tok == token.CONST {
+				kind = NameKindConst
+			}
+			// a spec declaring several names is documented once, from the first of them
+			for i, name := range s.Names {
+				if name.IsExported() {
+					decls = append(decls, exportedDecl{name: name, kind: kind, fullName: name.Name, doc: doc, group: group || i > 0})
+				}
+			}
+		}
+	}
+
+	return decls
+}
+
+// specDoc returns the doc comment of the spec and whether it documents a group of specs
+func specDoc(decl *ast.GenDecl, spec ast.Spec) (*ast.CommentGroup, bool) {
+	var doc, comment *ast.CommentGroup
+	switch s := spec.(type) {
+	case *ast.TypeSpec:
+		doc, comment = s.Doc, s.Comment
+	case *ast.ValueSpec:
+		doc, comment = s.Doc, s.Comment
+	}
+
+	switch {
+	case doc != nil:
+		return doc, false
+	case !decl.Lparen.IsValid():
+		return decl.Doc, false
+	case decl.Tok != token.TYPE && decl.Doc != nil:
+		return decl.Doc, true
+	case decl.Tok != token.TYPE && comment != nil:
+		// a line comment documents a constant or variable of a group
+		return comment, true
+	}
+
+	return nil, false
+}
+
+// startsWithName reports whether the doc comment starts with the name, after an article for types
+func startsWithName(doc *ast.CommentGroup, name, kind string) bool {
+	words := strings.Fields(doc.Text())
+	if len(words) > 1 && kind == NameKindType && articles[words[0]] {
+		words = words[1:]
+	}
+
+	return len(words) > 0 && strings.TrimRight(words[0], ".,:;") == name
+}
+
+func init() {
+	RegisterRule(DocCommentRuleName, func() Rule {
+		return NewDocCommentRule()
+	})
+}
@@ -18,11 +18,13 @@
 package ast
 
 import (
+	"fmt"
 	"go/ast"
 	"go/parser"
 	"go/token"
-
-	"github.com/sirupsen/logrus"
+	"go/types"
+	"path/filepath"
+	"sort"
 )
 
 type (
@@ -33,6 +35,18 @@ type (
 
 		// file of the source code
 		file *ast.File
+
+		// reportUnused reports the suppressions matching no finding
+		reportUnused bool
+
+		// syntaxErrors are the findings of the syntax errors of the file
+		syntaxErrors []Finding
+
+		// info is the type information of the file, nil if the file is not type-checked
+		info *types.Info
+
+		// pkgFiles are the files of the package of the file, nil if unknown
+		pkgFiles []*ast.File
 	}
 )
 
@@ -40,87 +54,176 @@ type (
 // path is the path of the source code, if not exists, pass an empty string
 // src is the source code, if not exists, pass nil
 // path and src must not be nil at the same time
-func NewEngine(path string, src any) *Engine {
+// syntax errors do not fail, the partial file is analyzed and the errors are reported as findings,
+// an error is only returned if the source code cannot be read
+func NewEngine(path string, src any) (*Engine, error) {
 	fileSet := token.NewFileSet()
-	file, err := parser.ParseFile(fileSet, path, src, parser.ParseComments)
-	if err != nil {
-		logrus.Errorf("parse file %s failed: %v", path, err)
-		panic(err)
+	file, err := parser.ParseFile(fileSet, path, src, parser.ParseComments|parser.AllErrors)
+	if file == nil {
+		return nil, fmt.Errorf("parse file %s failed: %w", path, err)
 	}
 
+	return &Engine{
+		fileSet:      fileSet,
+		file:         file,
+		syntaxErrors: ErrorFindings(err),
+	}, nil
+}
+
+// NewEngineFromFile creates a new Engine instance for a file already parsed in the file set
+func NewEngineFromFile(fileSet *token.FileSet, file *ast.File) *Engine {
 	return &Engine{
 		fileSet: fileSet,
 		file:    file,
 	}
 }
 
-// CheckIdentifiers checks if the identifiers' length is equal to 13
-// returns true if all identifiers' length is not equal to 13, otherwise false
-func (e *Engine) CheckIdentifiers() bool {
-	// check if identifier's length is equal to 13
-	noExists := false
-	ast.Inspect(e.file, func(n ast.Node) bool {
-		switch x := n.(type) {
-		case *ast.Ident:
-			if len(x.Name) == 13 {
-				noExists = true
-				return false
-			}
-		}
+// SetReportUnusedSuppressions enables reporting the suppression directives matching no finding
+func (e *Engine) SetReportUnusedSuppressions(enabled bool) {
+	e.reportUnused = enabled
+}
 
-		return true
-	})
+// SetTypesInfo sets the type information of the file, the rules requiring types are skipped without it
+func (e *Engine) SetTypesInfo(info *types.Info) {
+	e.info = info
+}
 
-	return !noExists
+// SetPackageFiles sets the files of the package of the file, parsed in the same file set,
+// for the rules comparing the declarations of the package
+func (e *Engine) SetPackageFiles(files []*ast.File) {
+	e.pkgFiles = files
 }
 
-// CheckControlFlow checks if the control flow (if, for, switch, select) is nested more than 4 times
-// returns true if control flow is not nested more than 4 times, otherwise false
-func (e *Engine) CheckControlFlow() bool {
-	// check if control flow (if, for, switch, select) is nested 4 times
-	maxDepth := e.checkNestingLevel(e.file, 0, 4)
-	return maxDepth <= 4
+// LoadPackageFiles parses the files of the package of the file into the file set of the engine,
+// the paths are the go files of its package in its directory, those declaring another package are skipped
+// and the partial syntax tree of a file with syntax errors is kept
+func (e *Engine) LoadPackageFiles(paths []string) error {
+	own := filepath.Clean(e.fileSet.File(e.file.Pos()).Name())
+	files := []*ast.File{e.file}
+	for _, path := range paths {
+		if filepath.Clean(path) == own {
+			continue
+		}
+
+		file, err := parser.ParseFile(e.fileSet, path, nil, parser.ParseComments)
+		if file == nil {
+			return fmt.Errorf("parse file %s failed: %w", path, err)
+		}
+		if file.Name.Name == e.file.Name.Name {
+			files = append(files, file)
+		}
+	}
+
+	// every file of the package sees the files in the same order
+	sort.Slice(files, func(i, j int) bool {
+		return e.fileSet.File(files[i].Pos()).Name() < e.fileSet.File(files[j].Pos()).Name()
+	})
+	e.pkgFiles = files
+
+	return nil
 }
 
-func (e *Engine) checkNestingLevel(parent ast.Node, depth, maxLevel int) int {
-	if depth > maxLevel {
-		return depth
+// Run runs the rules over the file in a single traversal and returns their findings sorted by position,
+// the syntax errors of the file are reported as findings too
+// findings matched by a //lint:ignore or //lint:file-ignore directive are dropped,
+// malformed directives are reported as findings
+func (e *Engine) Run(rules ...Rule) []Finding {
+	if e.info == nil {
+		rules = untypedRules(rules)
 	}
 
-	maxDepth := depth
+	pkgFiles := e.pkgFiles
+	if len(pkgFiles) == 0 {
+		pkgFiles = []*ast.File{e.file}
+	}
 
-	ast.Inspect(parent, func(node ast.Node) bool {
-		switch x := node.(type) {
-		case *ast.IfStmt:
-			currentDepth := e.checkNestingLevel(x.Body, depth+1, maxLevel)
-			if currentDepth > maxDepth {
-				maxDepth = currentDepth
-			}
+	walk := &walkState{}
+	contexts := make([]*Context, len(rules))
+	for i, rule := range rules {
+		contexts[i] = &Context{
+			FileSet: e.fileSet,
+			File:    e.file,
+			Package: pkgFiles,
+			rule:    rule,
+			walk:    walk,
+		}
+		if RequiresTypes(rule) {
+			contexts[i].TypesInfo = e.info
+		}
+	}
 
-			if x.Else != nil {
-				currentDepth := e.checkNestingLevel(x.Else, depth+1, maxLevel)
-				if currentDepth > maxDepth {
-					maxDepth = currentDepth
+	ast.Inspect(e.file, func(node ast.Node) bool {
+		if node == nil {
+			top := walk.stack[len(walk.stack)-1]
+			for i, rule := range rules {
+				if leaver, ok := rule.(LeaveRule); ok {
+					leaver.Leave(contexts[i], top)
 				}
 			}
-		case *ast.ForStmt:
-			currentDepth := e.checkNestingLevel(x.Body, depth+1, maxLevel)
-			if currentDepth > maxDepth {
-				maxDepth = currentDepth
-			}
-		case *ast.SwitchStmt:
-			currentDepth := e.checkNestingLevel(x.Body, depth+1, maxLevel)
-			if currentDepth > maxDepth {
-				maxDepth = currentDepth
-			}
-		case *ast.SelectStmt:
-			currentDepth := e.checkNestingLevel(x.Body, depth+1, maxLevel)
-			if currentDepth > maxDepth {
-				maxDepth = currentDepth
-			}
+			walk.stack = walk.stack[:len(walk.stack)-1]
+			return true
+		}
+
+		walk.stack = append(walk.stack, node)
+		for i, rule := range rules {
+			rule.Visit(contexts[i], node)
 		}
 		return true
 	})
 
-	return maxDepth
+	for i, rule := range rules {
+		if finisher, ok := rule.(FinishRule); ok {
+			finisher.Finish(contexts[i])
+		}
+	}
+
+	ran := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		ran[rule.Name()] = true
+	}
+
+	suppressions, findings := parseSuppressions(e.fileSet, e.file)
+	findings = append(findings, unknownSuppressions(suppressions, ran)...)
+	findings = append(findings, e.syntaxErrors...)
+	findings = append(findings, suppress(walk.findings, suppressions)...)
+	if e.reportUnused {
+		findings = append(findings, unusedSuppressions(suppressions, ran)...)
+	}
+
+	SortFindings(findings)
+	return findings
+}
+
+// untypedRules returns the rules not requiring type information
+func untypedRules(rules []Rule) []Rule {
+	untyped := make([]Rule, 0, len(rules))
+	for _, rule := range rules {
+		if !RequiresTypes(rule) {
+			untyped = append(untyped, rule)
+		}
+	}
+
+	return untyped
+}
+
+// CheckIdentifiers checks if the identifiers' length is equal to 13
+// returns true if all identifiers' length is not equal to 13, otherwise false
+func (e *Engine) CheckIdentifiers() bool {
+	return !hasFindings(e.Run(NewIdentLengthRule()), IdentLengthRuleName)
+}
+
+// CheckControlFlow checks if the control flow (if, for, switch, select) is nested more than 4 times
+// returns true if control flow is not nested more than 4 times, otherwise false
+func (e *Engine) CheckControlFlow() bool {
+	return !hasFindings(e.Run(NewNestingRule()), NestingRuleName)
+}
+
+func hasFindings(findings []Finding, rule string) bool {
+	for _, finding := range findings {
+		if finding.Rule == rule {
+			return true
+		}
+	}
+
+	return false
 }
@@ -44,7 +44,8 @@ func main() {
 	fmt.Println(idNotEqual13)
 }
 `
-		e := ast.NewEngine("", src)
+		e, err := ast.NewEngine("", src)
+		assert.NoError(err)
 
 		ok := e.CheckIdentifiers()
 		assert.False(ok)
@@ -63,7 +64,8 @@ func idNotEqual13() {
 	return
 }
 `
-		e := ast.NewEngine("", src)
+		e, err := ast.NewEngine("", src)
+		assert.NoError(err)
 
 		ok := e.CheckIdentifiers()
 		assert.False(ok)
@@ -78,7 +80,8 @@ type idEqual13xxxx struct {
 	Name string
 }
 `
-		e := ast.NewEngine("", src)
+		e, err := ast.NewEngine("", src)
+		assert.NoError(err)
 
 		ok := e.CheckIdentifiers()
 		assert.False(ok)
@@ -94,7 +97,8 @@ func idEqual13xxxx() {
 	return
 }
 `
-		e := ast.NewEngine("", src)
+		e, err := ast.NewEngine("", src)
+		assert.NoError(err)
 
 		ok := e.CheckIdentifiers()
 		assert.False(ok)
@@ -112,7 +116,8 @@ func main() {
 	return
 }
 `
-		e := ast.NewEngine("", src)
+		e, err := ast.NewEngine("", src)
+		assert.NoError(err)
 
 		ok := e.CheckIdentifiers()
 		assert.True(ok)
@@ -129,7 +134,8 @@ func main() {
 	fmt.Println(idNot13)
 	fmt.Println(idNOT13)
 }`
-		e := ast.NewEngine("", src)
+		e, err := ast.NewEngine("", src)
+		assert.NoError(err)
 
 		ok := e.CheckIdentifiers()
 		assert.True(ok)
@@ -157,7 +163,8 @@ func main() {
 	}
 }
 `
-		e := ast.NewEngine("", src)
+		e, err := ast.NewEngine("", src)
+		assert.NoError(err)
 
 		ok := e.CheckControlFlow()
 		assert.False(ok)
@@ -185,7 +192,8 @@ func main() {
 	}
 }
 `
-		e := ast.NewEngine("", src)
+		e, err := ast.NewEngine("", src)
+		assert.NoError(err)
 
 		ok := e.CheckControlFlow()
 		assert.False(ok)
@@ -204,7 +212,8 @@ func main() {
 	}
 }
 `
-		e := ast.NewEngine("", src)
+		e, err := ast.NewEngine("", src)
+		assert.NoError(err)
 		ok := e.CheckControlFlow()
 		assert.True(ok)
 	})
@@ -218,7 +227,8 @@ func main() {
 	}
 }
 `
-		e := ast.NewEngine("", src)
+		e, err := ast.NewEngine("", src)
+		assert.NoError(err)
 		ok := e.CheckControlFlow()
 		assert.True(ok)
 	})
@@ -240,7 +250,8 @@ func main() {
 	return
 }
 `
-		e := ast.NewEngine("", src)
+		e, err := ast.NewEngine("", src)
+		assert.NoError(err)
 
 		ok := e.CheckControlFlow()
 		assert.True(ok)
@@ -271,9 +282,46 @@ func main() {
 	return
 }
 `
-		e := ast.NewEngine("", src)
+		e, err := ast.NewEngine("", src)
+		assert.NoError(err)
 
 		ok := e.CheckControlFlow()
 		assert.False(ok)
 	})
 }
+
+// TestEngine_SyntaxError tests that a file with syntax errors is still analyzed and the errors are reported as findings
+func TestEngine_SyntaxError(t *testing.T) {
+	t.Run("Run with syntax errors", func(t *testing.T) {
+		assert := testAssert.New(t)
+		src := `
+package main
+
+func main() {
+	idEqual13xxxx := 1
+	if idEqual13xxxx == 1 {
+		idEqual13xxxx = idEqual13xxxx * ;
+	}
+}
+`
+		e, err := ast.NewEngine("broken.go", src)
+		assert.NoError(err)
+
+		findings := e.Run(&ast.IdentLengthRule{})
+		if assert.Len(findings, 6) {
+			assert.Equal(ast.IdentLengthRuleName, findings[0].Rule)
+			assert.Equal(5, findings[0].Position.Line)
+			assert.Equal(ast.SyntaxErrorRuleName, findings[4].Rule)
+			assert.Equal(ast.SeverityError, findings[4].Severity)
+			assert.Equal("broken.go", findings[4].Position.Filename)
+			assert.Equal(7, findings[4].Position.Line)
+		}
+	})
+
+	t.Run("Missing file", func(t *testing.T) {
+		assert := testAssert.New(t)
+
+		_, err := ast.NewEngine("testdata/missing.go", nil)
+		assert.Error(err)
+	})
+}
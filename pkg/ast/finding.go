@@ -0,0 +1,211 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ast
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/printer"
+	"go/scanner"
+	"go/token"
+	"go/types"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+type (
+	// Finding is a violation reported by a rule
+	Finding struct {
+		// Rule is the name of the rule reporting the finding
+		Rule string
+
+		// Severity is the severity of the finding
+		Severity Severity
+
+		// Message describes the violation
+		Message string
+
+		// Subject is the offending identifier or the first line of the offending statement
+		Subject string
+
+		// Position is the start position of the offending node
+		Position token.Position
+
+		// End is the end position of the offending node
+		End token.Position
+
+		// Fixes are the suggested fixes of the finding
+		Fixes []Fix `json:",omitempty"`
+
+		// Pos is the start of the offending node in the file set of the engine
+		Pos token.Pos `json:"-"`
+
+		// EndPos is the end of the offending node in the file set of the engine
+		EndPos token.Pos `json:"-"`
+	}
+
+	// Fix is a suggested fix of a finding
+	Fix struct {
+		// Message describes the fix
+		Message string
+
+		// Edits are the text edits of the fix, they must not overlap
+		Edits []TextEdit
+	}
+
+	// TextEdit replaces the bytes [Offset, End) of the file with NewText
+	TextEdit struct {
+		// Offset is the byte offset of the start of the replaced text
+		Offset int
+
+		// End is the byte offset of the end of the replaced text, equal to Offset for an insertion
+		End int
+
+		// NewText is the replacement text
+		NewText string
+	}
+)
+
+const (
+	// SyntaxErrorRuleName is the rule name of the findings reported for syntax errors
+	SyntaxErrorRuleName = "syntax-error"
+
+	// TypeErrorRuleName is the rule name of the findings reported for type errors
+	TypeErrorRuleName = "type-error"
+
+	// maxSubjectLength is the maximum length of the subject of a finding
+	maxSubjectLength = 80
+)
+
+// ErrorFindings converts the syntax and type errors to findings at their positions,
+// err may be a scanner.ErrorList, a types.Error, a packages.Error or errors joined with errors.Join
+func ErrorFindings(err error) []Finding {
+	if err == nil {
+		return nil
+	}
+
+	var findings []Finding
+	switch x := err.(type) {
+	case scanner.ErrorList:
+		// keep the first error of each line, the following ones are mostly caused by it
+		list := append(scanner.ErrorList(nil), x...)
+		list.RemoveMultiples()
+		for _, e := range list {
+			findings = append(findings, errorFinding(SyntaxErrorRuleName, e.Pos, e.Msg, token.NoPos))
+		}
+	case *scanner.Error:
+		findings = append(findings, errorFinding(SyntaxErrorRuleName, x.Pos, x.Msg, token.NoPos))
+	case types.Error:
+		findings = append(findings, errorFinding(TypeErrorRuleName, x.Fset.Position(x.Pos), x.Msg, x.Pos))
+	case packages.Error:
+		rule := TypeErrorRuleName
+		if x.Kind == packages.ParseError {
+			rule = SyntaxErrorRuleName
+		}
+		findings = append(findings, errorFinding(rule, parsePosition(x.Pos), x.Msg, token.NoPos))
+	case interface{ Unwrap() []error }:
+		for _, e := range x.Unwrap() {
+			findings = append(findings, ErrorFindings(e)...)
+		}
+	default:
+		findings = append(findings, errorFinding(SyntaxErrorRuleName, token.Position{}, err.Error(), token.NoPos))
+	}
+
+	return findings
+}
+
+func errorFinding(rule string, position token.Position, message string, pos token.Pos) Finding {
+	return Finding{
+		Rule:     rule,
+		Severity: SeverityError,
+		Message:  message,
+		Position: position,
+		End:      position,
+		Pos:      pos,
+		EndPos:   pos,
+	}
+}
+
+// parsePosition parses a "file:line:column" or "file:line" position
+func parsePosition(value string) token.Position {
+	parts := strings.Split(value, ":")
+	n := len(parts)
+	if n >= 3 {
+		line, lineErr := strconv.Atoi(parts[n-2])
+		column, columnErr := strconv.Atoi(parts[n-1])
+		if lineErr == nil && columnErr == nil {
+			return token.Position{Filename: strings.Join(parts[:n-2], ":"), Line: line, Column: column}
+		}
+	}
+	if n >= 2 {
+		if line, err := strconv.Atoi(parts[n-1]); err == nil {
+			return token.Position{Filename: strings.Join(parts[:n-1], ":"), Line: line}
+		}
+	}
+
+	return token.Position{Filename: value}
+}
+
+// String returns the finding in the "file:line:column: message (rule)" format
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s (%s)", f.Position, f.Message, f.Rule)
+}
+
+// SortFindings sorts the findings by file, line, column and rule
+func SortFindings(findings []Finding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.Position.Filename != b.Position.Filename {
+			return a.Position.Filename < b.Position.Filename
+		}
+		if a.Position.Line != b.Position.Line {
+			return a.Position.Line < b.Position.Line
+		}
+		if a.Position.Column != b.Position.Column {
+			return a.Position.Column < b.Position.Column
+		}
+		return a.Rule < b.Rule
+	})
+}
+
+// subjectOf returns the name of an identifier, or the first line of the source of other nodes
+func subjectOf(fileSet *token.FileSet, node ast.Node) string {
+	switch x := node.(type) {
+	case *ast.Ident:
+		return x.Name
+	case *ast.File:
+		return x.Name.Name
+	}
+
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fileSet, node); err != nil {
+		return ""
+	}
+
+	subject, _, _ := strings.Cut(buf.String(), "\n")
+	subject = strings.TrimSpace(strings.TrimSuffix(subject, "{"))
+	if len(subject) > maxSubjectLength {
+		subject = subject[:maxSubjectLength] + "..."
+	}
+
+	return subject
+}
@@ -0,0 +1,89 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ast_test
+
+import (
+	"testing"
+
+	testAssert "github.com/stretchr/testify/assert"
+
+	"github.com/LokiWager/analysis-demo/pkg/ast"
+)
+
+// TestFinding_Position tests the position and subject of the findings
+func TestFinding_Position(t *testing.T) {
+	t.Run("Findings for every identifier", func(t *testing.T) {
+		assert := testAssert.New(t)
+		src := `package main
+
+func idEqual13xxxx() {
+	anotherIdEqua := "abcdefghijklm"
+	_ = anotherIdEqua
+}
+`
+		e, err := ast.NewEngine("example.go", src)
+		assert.NoError(err)
+		findings := e.Run(ast.NewIdentLengthRule())
+
+		assert.Len(findings, 3)
+		assert.Equal("example.go", findings[0].Position.Filename)
+		assert.Equal(3, findings[0].Position.Line)
+		assert.Equal(6, findings[0].Position.Column)
+		assert.Equal("idEqual13xxxx", findings[0].Subject)
+		assert.Equal(ast.IdentLengthRuleName, findings[0].Rule)
+		assert.Equal(ast.SeverityWarning, findings[0].Severity)
+		assert.Equal("example.go:3:6: identifier idEqual13xxxx has length 13 (ident-length)", findings[0].String())
+
+		assert.Equal(4, findings[1].Position.Line)
+		assert.Equal(2, findings[1].Position.Column)
+		assert.Equal("anotherIdEqua", findings[1].Subject)
+		assert.Equal(5, findings[2].Position.Line)
+		assert.Equal(6, findings[2].Position.Column)
+	})
+
+	t.Run("Finding for nested statement", func(t *testing.T) {
+		assert := testAssert.New(t)
+		src := `package main
+
+func main() {
+	for i := 0; i < 10; i++ {
+		if i > 1 {
+			switch i {
+			case 2:
+				select {
+				default:
+					if i%2 == 0 {
+						return
+					}
+				}
+			}
+		}
+	}
+}
+`
+		e, err := ast.NewEngine("example.go", src)
+		assert.NoError(err)
+		findings := e.Run(ast.NewNestingRule())
+
+		assert.Len(findings, 1)
+		assert.Equal(10, findings[0].Position.Line)
+		assert.Equal(6, findings[0].Position.Column)
+		assert.Equal("if i%2 == 0", findings[0].Subject)
+		assert.Equal(12, findings[0].End.Line)
+	})
+}
@@ -0,0 +1,117 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ast
+
+import (
+	"go/ast"
+)
+
+type (
+	// FunctionLengthRule reports functions with more than max-statements statements (default 50)
+	// or more than max-lines lines in their body (default 80)
+	FunctionLengthRule struct {
+		// maxStatements is the maximum allowed number of statements of a function
+		maxStatements int
+
+		// maxLines is the maximum allowed number of lines of a function body
+		maxLines int
+	}
+)
+
+const (
+	// FunctionLengthRuleName is the name of the FunctionLengthRule
+	FunctionLengthRuleName = "function-length"
+
+	// defaultMaxStatements is the default maximum allowed number of statements of a function
+	defaultMaxStatements = 50
+
+	// defaultMaxLines is the default maximum allowed number of lines of a function body
+	defaultMaxLines = 80
+)
+
+// NewFunctionLengthRule creates a new FunctionLengthRule instance with the default limits
+func NewFunctionLengthRule() *FunctionLengthRule {
+	return &FunctionLengthRule{maxStatements: defaultMaxStatements, maxLines: defaultMaxLines}
+}
+
+// Name returns the name of the rule
+func (r *FunctionLengthRule) Name() string {
+	return FunctionLengthRuleName
+}
+
+// Doc returns the documentation of the rule
+func (r *FunctionLengthRule) Doc() string {
+	return "reports functions with more than max-statements statements (default 50) or max-lines lines (default 80)"
+}
+
+// Severity returns the default severity of the rule
+func (r *FunctionLengthRule) Severity() Severity {
+	return SeverityWarning
+}
+
+// Configure sets the max-statements and max-lines parameters, 0 disables a limit
+func (r *FunctionLengthRule) Configure(params Params) error {
+	return params.Limits(0, map[string]*int{"max-statements": &r.maxStatements, "max-lines": &r.maxLines})
+}
+
+// Visit measures every function declaration
+func (r *FunctionLengthRule) Visit(ctx *Context, node ast.Node) {
+	fn, ok := node.(*ast.FuncDecl)
+	if !ok || fn.Body == nil {
+		return
+	}
+
+	if statements := countStatements(fn.Body); r.maxStatements > 0 && statements > r.maxStatements {
+		ctx.Reportf(fn.Name, "function %s has %d statements, more than %d", FuncName(fn), statements, r.maxStatements)
+	}
+	if lines := bodyLines(ctx, fn.Body); r.maxLines > 0 && lines > r.maxLines {
+		ctx.Reportf(fn.Name, "function %s has %d lines, more than %d", FuncName(fn), lines, r.maxLines)
+	}
+}
+
+// countStatements returns the number of statements in the body, including the ones of nested blocks and function
+// literals, blocks and clauses only group statements and are not counted
+func countStatements(body *ast.BlockStmt) int {
+	count := 0
+	ast.Inspect(body, func(node ast.Node) bool {
+		switch node.(type) {
+		case *ast.BlockStmt, *ast.CaseClause, *ast.CommClause, *ast.EmptyStmt, *ast.LabeledStmt:
+		case ast.Stmt:
+			count++
+		}
+		return true
+	})
+
+	return count
+}
+
+// bodyLines returns the number of lines between the braces of the body
+func bodyLines(ctx *Context, body *ast.BlockStmt) int {
+	lines := ctx.FileSet.Position(body.Rbrace).Line - ctx.FileSet.Position(body.Lbrace).Line - 1
+	if lines < 0 {
+		return 0
+	}
+
+	return lines
+}
+
+func init() {
+	RegisterRule(FunctionLengthRuleName, func() Rule {
+		return NewFunctionLengthRule()
+	})
+}
@@ -0,0 +1,109 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ast
+
+import (
+	"go/ast"
+)
+
+type (
+	// FunctionParamsRule reports functions with more than max-params parameters (default 5)
+	// or more than max-results results (default 3)
+	FunctionParamsRule struct {
+		// maxParams is the maximum allowed number of parameters of a function
+		maxParams int
+
+		// maxResults is the maximum allowed number of results of a function
+		maxResults int
+	}
+)
+
+const (
+	// FunctionParamsRuleName is the name of the FunctionParamsRule
+	FunctionParamsRuleName = "function-params"
+
+	// defaultMaxParams is the default maximum allowed number of parameters of a function
+	defaultMaxParams = 5
+
+	// defaultMaxResults is the default maximum allowed number of results of a function
+	defaultMaxResults = 3
+)
+
+// NewFunctionParamsRule creates a new FunctionParamsRule instance with the default limits
+func NewFunctionParamsRule() *FunctionParamsRule {
+	return &FunctionParamsRule{maxParams: defaultMaxParams, maxResults: defaultMaxResults}
+}
+
+// Name returns the name of the rule
+func (r *FunctionParamsRule) Name() string {
+	return FunctionParamsRuleName
+}
+
+// Doc returns the documentation of the rule
+func (r *FunctionParamsRule) Doc() string {
+	return "reports functions with more than max-params parameters (default 5) or max-results results (default 3)"
+}
+
+// Severity returns the default severity of the rule
+func (r *FunctionParamsRule) Severity() Severity {
+	return SeverityWarning
+}
+
+// Configure sets the max-params and max-results parameters, 0 disables a limit
+func (r *FunctionParamsRule) Configure(params Params) error {
+	return params.Limits(0, map[string]*int{"max-params": &r.maxParams, "max-results": &r.maxResults})
+}
+
+// Visit counts the parameters and results of every function declaration, the receiver is not a parameter
+func (r *FunctionParamsRule) Visit(ctx *Context, node ast.Node) {
+	fn, ok := node.(*ast.FuncDecl)
+	if !ok {
+		return
+	}
+
+	if params := countFields(fn.Type.Params); r.maxParams > 0 && params > r.maxParams {
+		ctx.Reportf(fn.Name, "function %s has %d parameters, more than %d", FuncName(fn), params, r.maxParams)
+	}
+	if results := countFields(fn.Type.Results); r.maxResults > 0 && results > r.maxResults {
+		ctx.Reportf(fn.Name, "function %s has %d results, more than %d", FuncName(fn), results, r.maxResults)
+	}
+}
+
+// countFields returns the number of parameters or results of the list, a, b int counts as 2
+func countFields(fields *ast.FieldList) int {
+	if fields == nil {
+		return 0
+	}
+
+	count := 0
+	for _, field := range fields.List {
+		if len(field.Names) == 0 {
+			count++
+		} else {
+			count += len(field.Names)
+		}
+	}
+
+	return count
+}
+
+func init() {
+	RegisterRule(FunctionParamsRuleName, func() Rule {
+		return NewFunctionParamsRule()
+	})
+}
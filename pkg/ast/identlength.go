@@ -0,0 +1,73 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ast
+
+import (
+	"go/ast"
+)
+
+type (
+	// IdentLengthRule reports identifiers whose length is equal to 13
+	IdentLengthRule struct{}
+)
+
+const (
+	// IdentLengthRuleName is the name of the IdentLengthRule
+	IdentLengthRuleName = "ident-length"
+
+	// forbiddenIdentLength is the forbidden length of identifiers
+	forbiddenIdentLength = 13
+)
+
+// NewIdentLengthRule creates a new IdentLengthRule instance
+func NewIdentLengthRule() *IdentLengthRule {
+	return &IdentLengthRule{}
+}
+
+// Name returns the name of the rule
+func (r *IdentLengthRule) Name() string {
+	return IdentLengthRuleName
+}
+
+// Doc returns the documentation of the rule
+func (r *IdentLengthRule) Doc() string {
+	return "reports identifiers whose length is equal to 13"
+}
+
+// Severity returns the default severity of the rule
+func (r *IdentLengthRule) Severity() Severity {
+	return SeverityWarning
+}
+
+// Visit checks the length of every identifier
+func (r *IdentLengthRule) Visit(ctx *Context, node ast.Node) {
+	ident, ok := node.(*ast.Ident)
+	if !ok {
+		return
+	}
+
+	if len(ident.Name) == forbiddenIdentLength {
+		ctx.Reportf(ident, "identifier %s has length %d", ident.Name, forbiddenIdentLength)
+	}
+}
+
+func init() {
+	RegisterRule(IdentLengthRuleName, func() Rule {
+		return NewIdentLengthRule()
+	})
+}
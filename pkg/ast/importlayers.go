@@ -0,0 +1,279 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ast
+
+import (
+	"bufio"
+	"fmt"
+	"go/ast"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/LokiWager/analysis-demo/pkg/config"
+)
+
+type (
+	// ImportLayersRule reports the imports crossing the configured layers:
+	// layers.<layer> are the globs of the packages of a layer,
+	// deny.<layer> are the layers or globs the packages of the layer must not import,
+	// allow.<layer> are the only layers or globs whose packages may import the layer
+	// globs match import paths and, for the packages of the module, their directory relative to the module root
+	ImportLayersRule struct {
+		// layers are the globs of the packages of every layer
+		layers map[string][]string
+
+		// deny are the layers or globs every layer must not import
+		deny map[string][]string
+
+		// allow are the only layers or globs allowed to import every layer
+		allow map[string][]string
+	}
+
+	// module is the module of a directory read from its go.mod file
+	module struct {
+		// root is the directory of the go.mod file
+		root string
+
+		// path is the module path
+		path string
+	}
+)
+
+const (
+	// ImportLayersRuleName is the name of the ImportLayersRule
+	ImportLayersRuleName = "import-layers"
+)
+
+// modules caches the modules of the directories, rules are created for every file
+var modules sync.Map
+
+// NewImportLayersRule creates a new ImportLayersRule instance without layers, it reports nothing until configured
+func NewImportLayersRule() *ImportLayersRule {
+	return &ImportLayersRule{}
+}
+
+// Name returns the name of the rule
+func (r *ImportLayersRule) Name() string {
+	return ImportLayersRuleName
+}
+
+// Doc returns the documentation of the rule
+func (r *ImportLayersRule) Doc() string {
+	return "reports imports denied between the configured layers or of layers restricted to allowed importers"
+}
+
+// Severity returns the default severity of the rule
+func (r *ImportLayersRule) Severity() Severity {
+	return SeverityError
+}
+
+// Configure sets the layers.<layer>, deny.<layer> and allow.<layer> parameters,
+// deny and allow take the names of layers or globs
+func (r *ImportLayersRule) Configure(params Params) error {
+	lists := map[string]map[string][]string{"layers": {}, "deny": {}, "allow": {}}
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		kind, layer, found := strings.Cut(key, ".")
+		if _, known := lists[kind]; !found || !known || layer == "" {
+			return fmt.Errorf("unknown parameter %s, want layers.<layer>, deny.<layer> or allow.<layer>", key)
+		}
+
+		values, err := params.Strings(key, nil)
+		if err != nil {
+			return err
+		}
+		for _, value := range values {
+			if err := config.CheckGlob(value); err != nil {
+				return err
+			}
+		}
+		lists[kind][layer] = values
+	}
+
+	for _, kind := range []string{"deny", "allow"} {
+		for layer := range lists[kind] {
+			if _, exists := lists["layers"][layer]; !exists {
+				return fmt.Errorf("%s.%s: layer %s is not defined", kind, layer, layer)
+			}
+		}
+	}
+	r.layers, r.deny, r.allow = lists["layers"], lists["deny"], lists["allow"]
+
+	return nil
+}
+
+// Visit checks every import of the file against the layers of the file
+func (r *ImportLayersRule) Visit(ctx *Context, node ast.Node) {
+	spec, ok := node.(*ast.ImportSpec)
+	if !ok || len(r.layers) == 0 {
+		return
+	}
+	imported, err := strconv.Unquote(spec.Path.Value)
+	if err != nil {
+		return
+	}
+
+	mod := findModule(filepath.Dir(ctx.FileSet.Position(ctx.File.Pos()).Filename))
+	importer := mod.names(filepath.Dir(ctx.FileSet.Position(ctx.File.Pos()).Filename))
+	target := mod.importNames(imported)
+
+	for _, layer := range r.layersOf(importer) {
+		for _, entry := range r.deny[layer] {
+			if r.matches(entry, target) {
+				ctx.Reportf(spec.Path, "layer %s must not import %s, denied by %s", layer, imported, entry)
+				return
+			}
+		}
+	}
+
+	for _, layer := range r.layersOf(target) {
+		allowed, restricted := r.allow[layer]
+		if !restricted || r.matches(layer, importer) {
+			continue
+		}
+
+		permitted := false
+		for _, entry := range allowed {
+			permitted = permitted || r.matches(entry, importer)
+		}
+		if !permitted {
+			ctx.Reportf(spec.Path, "layer %s of %s may only be imported by %s", layer, imported, strings.Join(allowed, ", "))
+			return
+		}
+	}
+}
+
+// layersOf returns the layers of the package in alphabetical order, the package is given by its names
+func (r *ImportLayersRule) layersOf(names []string) []string {
+	var layers []string
+	for layer := range r.layers {
+		if r.matches(layer, names) {
+			layers = append(layers, layer)
+		}
+	}
+	sort.Strings(layers)
+
+	return layers
+}
+
+// matches reports whether one of the names of the package matches the layer or the glob of the entry
+func (r *ImportLayersRule) matches(entry string, names []string) bool {
+	globs, isLayer := r.layers[entry]
+	if !isLayer {
+		globs = []string{entry}
+	}
+
+	for _, glob := range globs {
+		for _, name := range names {
+			if config.MatchGlob(glob, name) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// findModule returns the module of the directory, the zero module if it has none
+func findModule(dir string) module {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return module{}
+	}
+	if cached, ok := modules.Load(abs); ok {
+		return cached.(module)
+	}
+
+	mod := module{}
+	if path := modulePath(filepath.Join(abs, "go.mod")); path != "" {
+		mod = module{root: abs, path: path}
+	} else if parent := filepath.Dir(abs); parent != abs {
+		mod = findModule(parent)
+	}
+	modules.Store(abs, mod)
+
+	return mod
+}
+
+// modulePath returns the module path declared by the go.mod file, empty if it cannot be read
+func modulePath(file string) string {
+	f, err := os.Open(file)
+	if err != nil {
+		return ""
+	}
+	defer f.Close() // read only
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if rest, found := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "module"); found {
+			return strings.Trim(strings.TrimSpace(rest), `"`)
+		}
+	}
+
+	return ""
+}
+
+// names returns the names of the package in the directory: its directory relative to the module root
+// and its import path
+func (m module) names(dir string) []string {
+	abs, err := filepath.Abs(dir)
+	if m.root == "" || err != nil {
+		return []string{filepath.ToSlash(dir)}
+	}
+
+	rel, err := filepath.Rel(m.root, abs)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return []string{filepath.ToSlash(abs)}
+	}
+	rel = filepath.ToSlash(rel)
+
+	return []string{rel, path.Join(m.path, rel)}
+}
+
+// importNames returns the names of the imported package: its import path and,
+// for a package of the module, its directory relative to the module root
+func (m module) importNames(imported string) []string {
+	if m.path == "" {
+		return []string{imported}
+	}
+
+	if imported == m.path {
+		return []string{imported, "."}
+	}
+	if rel, found := strings.CutPrefix(imported, m.path+"/"); found {
+		return []string{imported, rel}
+	}
+
+	return []string{imported}
+}
+
+func init() {
+	RegisterRule(ImportLayersRuleName, func() Rule {
+		return NewImportLayersRule()
+	})
+}
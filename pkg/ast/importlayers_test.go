@@ -0,0 +1,100 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ast_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	testAssert "github.com/stretchr/testify/assert"
+
+	"github.com/LokiWager/analysis-demo/pkg/ast"
+)
+
+// TestImportLayersRule tests the layers of a module importing across them
+func TestImportLayersRule(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"go.mod":                           "module example.com/app\n\ngo 1.22\n",
+		"pkg/typechecker/checker.go":       "package typechecker\n\nimport (\n\t\"fmt\"\n\t\"example.com/app/pkg/service\"\n)\n",
+		"pkg/service/service.go":           "package service\n\nimport (\n\t\"example.com/app/cmd/lint\"\n\t\"go.mongodb.org/mongo-driver/v2/mongo\"\n)\n",
+		"pkg/utils/mongodbtool/mdbtool.go": "package mongodbtool\n\nimport \"go.mongodb.org/mongo-driver/v2/mongo\"\n",
+		"cmd/lint/main.go":                 "package main\n\nimport \"example.com/app/pkg/service\"\n",
+	}
+	for name, content := range files {
+		file := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			t.Fatalf("mkdir failed: %v", err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s failed: %v", name, err)
+		}
+	}
+
+	params := ast.Params{
+		"layers.cmd":         "cmd/**",
+		"layers.pkg":         "pkg/**",
+		"layers.service":     "pkg/service",
+		"layers.typechecker": "pkg/typechecker",
+		"layers.mongo":       "go.mongodb.org/mongo-driver/**",
+		"deny.pkg":           "cmd",
+		"deny.typechecker":   []any{"service"},
+		"allow.mongo":        "pkg/utils/mongodbtool",
+	}
+	tests := []struct {
+		file     string
+		expected []string
+	}{
+		{
+			file:     "pkg/typechecker/checker.go",
+			expected: []string{"layer typechecker must not import example.com/app/pkg/service, denied by service"},
+		},
+		{
+			file: "pkg/service/service.go",
+			expected: []string{
+				"layer pkg must not import example.com/app/cmd/lint, denied by cmd",
+				"layer mongo of go.mongodb.org/mongo-driver/v2/mongo may only be imported by pkg/utils/mongodbtool",
+			},
+		},
+		{file: "pkg/utils/mongodbtool/mdbtool.go"},
+		{file: "cmd/lint/main.go"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.file, func(t *testing.T) {
+			assert := testAssert.New(t)
+			rule := ast.NewImportLayersRule()
+			assert.NoError(rule.Configure(params))
+
+			e, err := ast.NewEngine(filepath.Join(root, test.file), nil)
+			assert.NoError(err)
+			var messages []string
+			for _, finding := range e.Run(rule) {
+				messages = append(messages, finding.Message)
+			}
+			assert.Equal(test.expected, messages)
+		})
+	}
+
+	rule := ast.NewImportLayersRule()
+	assert := testAssert.New(t)
+	assert.Error(rule.Configure(ast.Params{"deny.pkg": "cmd"}))
+	assert.Error(rule.Configure(ast.Params{"layer.pkg": "pkg/**"}))
+	assert.Error(rule.Configure(ast.Params{"layers.pkg": 3}))
+}
@@ -0,0 +1,318 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ast
+
+import (
+	"fmt"
+	"go/ast"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	// LicenseHeaderRule reports files whose leading comment, after the build constraints,
+	// does not match the license header template, its fix inserts or updates the header
+	// the {year} placeholder of the template matches a year or a range of years like 2020-2024,
+	// the {holder} placeholder matches the configured holder, or any holder if none
+	LicenseHeaderRule struct {
+		// template are the lines of the header without the comment markers
+		template []string
+
+		// patterns match the lines of the template
+		patterns []*regexp.Regexp
+
+		// holder is the copyright holder, any holder is accepted if empty and the header cannot be fixed
+		holder string
+
+		// year is the year of the inserted headers
+		year string
+	}
+)
+
+const (
+	// LicenseHeaderRuleName is the name of the LicenseHeaderRule
+	LicenseHeaderRuleName = "license-header"
+
+	// yearPlaceholder is replaced by a year or a range of years
+	yearPlaceholder = "{year}"
+
+	// holderPlaceholder is replaced by the copyright holder
+	holderPlaceholder = "{holder}"
+)
+
+// defaultLicenseTemplate is the Apache License 2.0 header
+const defaultLicenseTemplate = `Copyright (c) {year}, {holder}
+All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.`
+
+// yearPattern matches a year or a range of years
+var yearPattern = regexp.MustCompile(`\d{4}(?:\s*-\s*\d{4})?`)
+
+// NewLicenseHeaderRule creates a new LicenseHeaderRule instance with the Apache License 2.0 template and any holder
+func NewLicenseHeaderRule() *LicenseHeaderRule {
+	r := &LicenseHeaderRule{year: strconv.Itoa(time.Now().Year())}
+	r.setTemplate(defaultLicenseTemplate)
+
+	return r
+}
+
+// Name returns the name of the rule
+func (r *LicenseHeaderRule) Name() string {
+	return LicenseHeaderRuleName
+}
+
+// Doc returns the documentation of the rule
+func (r *LicenseHeaderRule) Doc() string {
+	return "reports files without the license header of the template, with {year} and {holder} placeholders"
+}
+
+// Severity returns the default severity of the rule
+func (r *LicenseHeaderRule) Severity() Severity {
+	return SeverityWarning
+}
+
+// Configure sets the template, holder and year parameters
+func (r *LicenseHeaderRule) Configure(params Params) error {
+	if err := params.Check("template", "holder", "year"); err != nil {
+		return err
+	}
+
+	template, err := params.String("template", strings.Join(r.template, "\n"))
+	if err != nil {
+		return err
+	}
+	if r.holder, err = params.String("holder", r.holder); err != nil {
+		return err
+	}
+	if r.year, err = params.String("year", r.year); err != nil {
+		return err
+	}
+	if !yearPattern.MatchString(r.year) {
+		return fmt.Errorf("year %s is not a year or a range of years", r.year)
+	}
+	// the patterns of the template match the configured holder
+	if strings.TrimSpace(template) == "" {
+		return fmt.Errorf("template must not be empty")
+	}
+	r.setTemplate(template)
+
+	return nil
+}
+
+// setTemplate sets the lines of the template and compiles their patterns
+func (r *LicenseHeaderRule) setTemplate(template string) {
+	r.template = trimBlankLines(strings.Split(template, "\n"))
+
+	holder := `(.+)`
+	if r.holder != "" {
+		holder = "(" + regexp.QuoteMeta(r.holder) + ")"
+	}
+	r.patterns = make([]*regexp.Regexp, 0, len(r.template))
+	for _, line := range r.template {
+		pattern := regexp.QuoteMeta(line)
+		pattern = strings.ReplaceAll(pattern, regexp.QuoteMeta(yearPlaceholder), "("+yearPattern.String()+")")
+		pattern = strings.ReplaceAll(pattern, regexp.QuoteMeta(holderPlaceholder), holder)
+		r.patterns = append(r.patterns, regexp.MustCompile("^"+pattern+"$"))
+	}
+}
+
+// Visit checks the leading comment of the file
+func (r *LicenseHeaderRule) Visit(ctx *Context, node ast.Node) {
+	file, ok := node.(*ast.File)
+	if !ok {
+		return
+	}
+
+	// a leading comment which is not a copyright notice is kept, the header is inserted before it
+	header := leadingComments(file)
+	lines := commentLines(header)
+	if len(header) == 0 || !strings.Contains(strings.ToLower(strings.Join(lines, "\n")), "copyright") &&
+		r.mismatch(lines) >= 0 {
+		insert := file.Package
+		if len(header) > 0 {
+			insert = header[0].Pos()
+		}
+
+		var fixes []Fix
+		if text, ok := r.render(nil); ok {
+			fixes = append(fixes, Fix{
+				Message: "insert the license header",
+				Edits:   []TextEdit{{Offset: ctx.Offset(insert), End: ctx.Offset(insert), NewText: text + "\n\n"}},
+			})
+		}
+		ctx.ReportWithFixes(file.Name, "missing license header", fixes...)
+		return
+	}
+
+	line := r.mismatch(lines)
+	if line < 0 {
+		return
+	}
+
+	var fixes []Fix
+	if text, ok := r.render(lines); ok {
+		fixes = append(fixes, Fix{
+			Message: "update the license header",
+			Edits: []TextEdit{{
+				Offset:  ctx.Offset(header[0].Pos()),
+				End:     ctx.Offset(header[len(header)-1].End()),
+				NewText: text,
+			}},
+		})
+	}
+	expected := ""
+	if line < len(r.template) {
+		expected = r.template[line]
+	}
+	ctx.ReportWithFixes(commentAt(header, line), fmt.Sprintf("license header does not match the template, line %d should be %q",
+		line+1, expected), fixes...)
+}
+
+// mismatch returns the index of the first line not matching the template, -1 if all lines match
+func (r *LicenseHeaderRule) mismatch(lines []string) int {
+	for i, pattern := range r.patterns {
+		if i >= len(lines) || !pattern.MatchString(lines[i]) {
+			return i
+		}
+	}
+	if len(lines) > len(r.patterns) {
+		return len(r.patterns)
+	}
+
+	return -1
+}
+
+// render renders the header as a block comment, keeping the year of the current header if it has one,
+// false if the holder is unknown
+func (r *LicenseHeaderRule) render(current []string) (string, bool) {
+	year, holder := r.year, r.holder
+	for _, line := range current {
+		if found := yearPattern.FindString(line); found != "" {
+			year = found
+			break
+		}
+	}
+	if holder == "" {
+		return "", false
+	}
+
+	var b strings.Builder
+	b.WriteString("/*\n")
+	for _, line := range r.template {
+		line = strings.ReplaceAll(line, yearPlaceholder, year)
+		line = strings.ReplaceAll(line, holderPlaceholder, holder)
+		if line == "" {
+			b.WriteString(" *\n")
+		} else {
+			b.WriteString(" * " + line + "\n")
+		}
+	}
+	b.WriteString(" */")
+
+	return b.String(), true
+}
+
+// leadingComments returns the comments of the first comment group before the package clause,
+// the build constraints are skipped
+func leadingComments(file *ast.File) []*ast.Comment {
+	for _, group := range file.Comments {
+		if group.Pos() > file.Package {
+			break
+		}
+
+		comments := group.List
+		for len(comments) > 0 && isBuildConstraint(comments[0].Text) {
+			comments = comments[1:]
+		}
+		if len(comments) > 0 {
+			return comments
+		}
+	}
+
+	return nil
+}
+
+// commentAt returns the comment of the line of the comments, the last comment if the line is beyond them
+func commentAt(comments []*ast.Comment, line int) *ast.Comment {
+	for _, comment := range comments {
+		n := len(commentLines([]*ast.Comment{comment}))
+		if line < n {
+			return comment
+		}
+		line -= n
+	}
+
+	return comments[len(comments)-1]
+}
+
+// isBuildConstraint reports whether the comment is a //go:build or // +build line
+func isBuildConstraint(text string) bool {
+	return strings.HasPrefix(text, "//go:build") || strings.HasPrefix(text, "// +build")
+}
+
+// commentLines returns the lines of the comments without the comment markers and the leading * of block comments
+func commentLines(comments []*ast.Comment) []string {
+	var lines []string
+	for _, comment := range comments {
+		if text, ok := strings.CutPrefix(comment.Text, "//"); ok {
+			lines = append(lines, strings.TrimRight(strings.TrimPrefix(text, " "), " \t"))
+			continue
+		}
+
+		text := strings.TrimSuffix(strings.TrimPrefix(comment.Text, "/*"), "*/")
+		var block []string
+		for _, line := range strings.Split(text, "\n") {
+			line = strings.TrimPrefix(strings.TrimLeft(line, " \t"), "*")
+			block = append(block, strings.TrimRight(strings.TrimPrefix(line, " "), " \t"))
+		}
+		lines = append(lines, trimBlankLines(block)...)
+	}
+
+	return lines
+}
+
+// trimBlankLines removes the leading and trailing blank lines
+func trimBlankLines(lines []string) []string {
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+func init() {
+	RegisterRule(LicenseHeaderRuleName, func() Rule {
+		return NewLicenseHeaderRule()
+	})
+}
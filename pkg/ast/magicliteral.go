@@ -0,0 +1,477 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ast
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"path"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type (
+	// MagicLiteralRule reports numeric and string literals outside const declarations,
+	// the allowed values, struct tags, import paths, array lengths, test files, format strings,
+	// the results of functions returning a literal and the arguments of logging and formatting calls
+	// are not reported. The rule is opt-in, it only runs when enabled by name
+	MagicLiteralRule struct {
+		// allowNumbers are the numbers that are not reported
+		allowNumbers []constant.Value
+
+		// allowStrings are the strings that are not reported, unquoted
+		allowStrings map[string]bool
+
+		// ignoreTests skips the _test.go files
+		ignoreTests bool
+
+		// ignoreCalls are the glob patterns of the calls whose arguments are not reported, like fmt.*
+		ignoreCalls []string
+
+		// names are the identifiers of the file, the extracted constants must not collide with them
+		names map[string]bool
+
+		// literals are the reported literals of the file, in order of appearance
+		literals []magicLiteral
+	}
+
+	// magicLiteral is a reported literal and the constant extracted from it
+	magicLiteral struct {
+		// expr is the literal, or the negation of a numeric literal
+		expr ast.Expr
+
+		// message is the message of the finding
+		message string
+
+		// name and text are the name and the value of the extracted constant
+		name, text string
+
+		// insert is the position the constant is declared at, before the top-level declaration of the literal
+		insert token.Pos
+	}
+)
+
+const (
+	// MagicLiteralRuleName is the name of the MagicLiteralRule
+	MagicLiteralRuleName = "magic-literal"
+)
+
+var (
+	// defaultAllowNumbers are the numbers allowed by default
+	defaultAllowNumbers = []string{"0", "1", "-1"}
+
+	// defaultAllowStrings are the strings allowed by default
+	defaultAllowStrings = []string{""}
+
+	// defaultIgnoreCalls are the calls whose arguments are messages or format strings rather than configuration
+	defaultIgnoreCalls = []string{
+		"panic", "fmt.*", "errors.*", "log.*", "logrus.*",
+		"*.Debug*", "*.Info*", "*.Warn*", "*.Error*", "*.Fatal*", "*.Panic*", "*.Print*",
+	}
+)
+
+// NewMagicLiteralRule creates a new MagicLiteralRule instance allowing 0, 1, -1 and the empty string
+func NewMagicLiteralRule() *MagicLiteralRule {
+	r := &MagicLiteralRule{ignoreTests: true, ignoreCalls: defaultIgnoreCalls}
+	// the defaults are valid numbers
+	_ = r.setAllowed(defaultAllowNumbers, defaultAllowStrings)
+
+	return r
+}
+
+// Name returns the name of the rule
+func (r *MagicLiteralRule) Name() string {
+	return MagicLiteralRuleName
+}
+
+// Doc returns the documentation of the rule
+func (r *MagicLiteralRule) Doc() string {
+	return "reports numeric and string literals outside const declarations, except 0, 1, -1 and the empty string, " +
+		"opt-in"
+}
+
+// Severity returns the default severity of the rule
+func (r *MagicLiteralRule) Severity() Severity {
+	return SeverityInfo
+}
+
+// Configure sets the allow-numbers, allow-strings, ignore-tests and ignore-calls parameters
+func (r *MagicLiteralRule) Configure(params Params) error {
+	if err := params.Check("allow-numbers", "allow-strings", "ignore-tests", "ignore-calls"); err != nil {
+		return err
+	}
+
+	numbers, err := params.Strings("allow-numbers", defaultAllowNumbers)
+	if err != nil {
+		return err
+	}
+	strs, err := params.Strings("allow-strings", defaultAllowStrings)
+	if err != nil {
+		return err
+	}
+	if r.ignoreTests, err = params.Bool("ignore-tests", r.ignoreTests); err != nil {
+		return err
+	}
+	if r.ignoreCalls, err = params.Strings("ignore-calls", r.ignoreCalls); err != nil {
+		return err
+	}
+	for _, pattern := range r.ignoreCalls {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid ignore-calls pattern %s: %w", pattern, err)
+		}
+	}
+
+	return r.setAllowed(numbers, strs)
+}
+
+// setAllowed parses the allowed numbers and strings
+func (r *MagicLiteralRule) setAllowed(numbers, strs []string) error {
+	r.allowNumbers = make([]constant.Value, 0, len(numbers))
+	for _, number := range numbers {
+		value := parseNumber(strings.TrimSpace(number))
+		if value.Kind() == constant.Unknown {
+			return fmt.Errorf("invalid allow-numbers value %s", number)
+		}
+		r.allowNumbers = append(r.allowNumbers, value)
+	}
+
+	r.allowStrings = make(map[string]bool, len(strs))
+	for _, str := range strs {
+		r.allowStrings[str] = true
+	}
+
+	return nil
+}
+
+// Visit reports the literals outside const declarations and the ignored contexts
+func (r *MagicLiteralRule) Visit(ctx *Context, node ast.Node) {
+	lit, ok := node.(*ast.BasicLit)
+	if !ok || lit.Kind == token.CHAR || (r.ignoreTests && isTestFile(ctx)) {
+		return
+	}
+
+	// a negative number is reported as a whole, -1 is not 1
+	var expr ast.Expr = lit
+	stack := ctx.Stack()
+	parents := stack[:len(stack)-1]
+	if unary, ok := ctx.Parent().(*ast.UnaryExpr); ok && unary.Op == token.SUB && lit.Kind != token.STRING {
+		expr = unary
+		parents = parents[:len(parents)-1]
+	}
+
+	if r.allowed(lit, expr != ast.Expr(lit)) || r.ignored(expr, parents) {
+		return
+	}
+
+	text := lit.Value
+	kind := "number"
+	if lit.Kind == token.STRING {
+		kind = "string"
+	} else if expr != ast.Expr(lit) {
+		text = "-" + text
+	}
+
+	r.literals = append(r.literals, magicLiteral{
+		expr:    expr,
+		message: fmt.Sprintf("magic %s %s, extract it to a named constant", kind, text),
+		name:    r.constName(ctx, lit, expr, parents),
+		text:    text,
+		insert:  constPos(expr, parents),
+	})
+}
+
+// Finish reports the literals, the first literal of a top-level declaration gets the fix
+// extracting the constants of all the literals of the declaration, so the fixes don't conflict
+func (r *MagicLiteralRule) Finish(ctx *Context) {
+	groups := make(map[token.Pos][]magicLiteral)
+	for _, literal := range r.literals {
+		groups[literal.insert] = append(groups[literal.insert], literal)
+	}
+
+	for _, literal := range r.literals {
+		group, first := groups[literal.insert]
+		if !first {
+			ctx.Report(literal.expr, literal.message)
+			continue
+		}
+		delete(groups, literal.insert)
+		ctx.ReportWithFixes(literal.expr, literal.message, extractConsts(ctx, group))
+	}
+	r.literals = nil
+}
+
+// allowed reports whether the value of the literal is allowed
+func (r *MagicLiteralRule) allowed(lit *ast.BasicLit, negative bool) bool {
+	if lit.Kind == token.STRING {
+		value, err := strconv.Unquote(lit.Value)
+		return err == nil && r.allowStrings[value]
+	}
+
+	value := constant.MakeFromLiteral(lit.Value, lit.Kind, 0)
+	if negative {
+		value = constant.UnaryOp(token.SUB, value, 0)
+	}
+	for _, allowed := range r.allowNumbers {
+		if constant.Compare(value, token.EQL, allowed) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ignored reports whether the literal is in a context where literals are expected
+func (r *MagicLiteralRule) ignored(expr ast.Expr, parents []ast.Node) bool {
+	for _, parent := range parents {
+		if decl, ok := parent.(*ast.GenDecl); ok && decl.Tok == token.CONST {
+			return true
+		}
+	}
+
+	switch parent := parents[len(parents)-1].(type) {
+	case *ast.ImportSpec:
+		return true
+	case *ast.Field:
+		return parent.Tag == expr
+	case *ast.ArrayType:
+		return parent.Len == expr
+	case *ast.ReturnStmt:
+		return returnsLiteral(parent, parents)
+	case *ast.CallExpr:
+		name := types.ExprString(parent.Fun)
+		if isFormatString(expr) && strings.HasSuffix(name, "f") {
+			return true
+		}
+		for _, pattern := range r.ignoreCalls {
+			// the patterns are validated by Configure
+			if matched, _ := path.Match(pattern, name); matched {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// constName returns the name of the constant extracted from the literal,
+// it is derived from the name the literal is assigned to or from the string itself
+func (r *MagicLiteralRule) constName(ctx *Context, lit *ast.BasicLit, expr ast.Expr, parents []ast.Node) string {
+	name := ""
+	if target := assignedName(expr, parents[len(parents)-1]); target != "" {
+		name = "default" + upperFirst(target)
+	} else if lit.Kind == token.STRING {
+		value, _ := strconv.Unquote(lit.Value) // the literal is valid, it was parsed
+		name = lowerCamelName(strings.FieldsFunc(value, func(c rune) bool {
+			return !unicode.IsLetter(c) && !unicode.IsDigit(c)
+		}))
+	}
+	if !token.IsIdentifier(name) || types.Universe.Lookup(name) != nil {
+		prefix := "magicNumber"
+		if lit.Kind == token.STRING {
+			prefix = "magicString"
+		}
+		name = prefix + upperFirst(name)
+	}
+
+	return r.uniqueName(ctx, name)
+}
+
+// uniqueName returns the name suffixed by a number if it is already used in the file
+func (r *MagicLiteralRule) uniqueName(ctx *Context, name string) string {
+	if r.names == nil {
+		r.names = map[string]bool{}
+		ast.Inspect(ctx.File, func(node ast.Node) bool {
+			if ident, ok := node.(*ast.Ident); ok {
+				r.names[ident.Name] = true
+			}
+			return true
+		})
+	}
+
+	unique := name
+	for i := 2; r.names[unique]; i++ {
+		unique = fmt.Sprintf("%s%d", name, i)
+	}
+	r.names[unique] = true
+
+	return unique
+}
+
+// constPos returns the position the constant extracted from the literal is declared at,
+// before the top-level declaration of the literal and its doc comment
+func constPos(expr ast.Expr, parents []ast.Node) token.Pos {
+	if len(parents) < 2 {
+		return expr.Pos()
+	}
+
+	switch decl := parents[1].(type) {
+	case *ast.FuncDecl:
+		if decl.Doc != nil {
+			return decl.Doc.Pos()
+		}
+	case *ast.GenDecl:
+		if decl.Doc != nil {
+			return decl.Doc.Pos()
+		}
+	}
+
+	return parents[1].Pos()
+}
+
+// extractConsts returns the fix declaring the constants of the literals sharing an insertion point
+// in a single declaration and replacing the literals with them
+func extractConsts(ctx *Context, literals []magicLiteral) Fix {
+	names := make([]string, 0, len(literals))
+	for _, literal := range literals {
+		names = append(names, literal.name)
+	}
+
+	decl := fmt.Sprintf("const %s = %s\n\n", literals[0].name, literals[0].text)
+	message := fmt.Sprintf("extract constant %s", literals[0].name)
+	if len(literals) > 1 {
+		var b strings.Builder
+		b.WriteString("const (\n")
+		for _, literal := range literals {
+			fmt.Fprintf(&b, "\t%s = %s\n", literal.name, literal.text)
+		}
+		b.WriteString(")\n\n")
+		decl = b.String()
+		message = fmt.Sprintf("extract constants %s", strings.Join(names, ", "))
+	}
+
+	insert := ctx.Offset(literals[0].insert)
+	edits := []TextEdit{{Offset: insert, End: insert, NewText: decl}}
+	for _, literal := range literals {
+		edits = append(edits, TextEdit{
+			Offset:  ctx.Offset(literal.expr.Pos()),
+			End:     ctx.Offset(literal.expr.End()),
+			NewText: literal.name,
+		})
+	}
+
+	return Fix{Message: message, Edits: edits}
+}
+
+// isFormatString reports whether the expression is a string literal with a formatting verb
+func isFormatString(expr ast.Expr) bool {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return false
+	}
+	value, err := strconv.Unquote(lit.Value)
+	return err == nil && strings.Contains(value, "%")
+}
+
+// returnsLiteral reports whether the return statement is the whole body of its function,
+// like Doc or Name methods the function stands for a constant
+func returnsLiteral(ret *ast.ReturnStmt, parents []ast.Node) bool {
+	if len(ret.Results) != 1 || len(parents) < 3 {
+		return false
+	}
+
+	body, ok := parents[len(parents)-2].(*ast.BlockStmt)
+	if !ok || len(body.List) != 1 {
+		return false
+	}
+	switch fn := parents[len(parents)-3].(type) {
+	case *ast.FuncDecl:
+		return fn.Body == body
+	case *ast.FuncLit:
+		return fn.Body == body
+	}
+
+	return false
+}
+
+// assignedName returns the name of the variable, field or key the expression is assigned to, if any
+func assignedName(expr ast.Expr, parent ast.Node) string {
+	switch parent := parent.(type) {
+	case *ast.ValueSpec:
+		for i, value := range parent.Values {
+			if value == expr && i < len(parent.Names) {
+				return parent.Names[i].Name
+			}
+		}
+	case *ast.AssignStmt:
+		for i, value := range parent.Rhs {
+			if value != expr || i >= len(parent.Lhs) {
+				continue
+			}
+			switch lhs := parent.Lhs[i].(type) {
+			case *ast.Ident:
+				return lhs.Name
+			case *ast.SelectorExpr:
+				return lhs.Sel.Name
+			}
+		}
+	case *ast.KeyValueExpr:
+		if key, ok := parent.Key.(*ast.Ident); ok && parent.Value == expr {
+			return key.Name
+		}
+	}
+
+	return ""
+}
+
+// parseNumber parses an allowed number, optionally negative
+func parseNumber(number string) constant.Value {
+	negative := strings.HasPrefix(number, "-")
+	number = strings.TrimPrefix(number, "-")
+
+	value := constant.MakeFromLiteral(number, token.INT, 0)
+	if value.Kind() == constant.Unknown {
+		value = constant.MakeFromLiteral(number, token.FLOAT, 0)
+	}
+	if negative && value.Kind() != constant.Unknown {
+		value = constant.UnaryOp(token.SUB, value, 0)
+	}
+
+	return value
+}
+
+// lowerCamelName joins the words in lowerCamelCase, e.g. seconds and per-host give secondsPerHost
+func lowerCamelName(words []string) string {
+	var b strings.Builder
+	for i, word := range words {
+		word = strings.ToLower(word)
+		if i > 0 {
+			word = upperFirst(word)
+		}
+		b.WriteString(word)
+	}
+
+	return b.String()
+}
+
+func upperFirst(name string) string {
+	if name == "" {
+		return name
+	}
+
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+func init() {
+	RegisterOptInRule(MagicLiteralRuleName, func() Rule {
+		return NewMagicLiteralRule()
+	})
+}
@@ -0,0 +1,102 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ast
+
+import (
+	"fmt"
+	"go/ast"
+)
+
+type (
+	// NakedReturnRule reports naked returns in functions with named results longer than max-lines lines (default 5)
+	NakedReturnRule struct {
+		// maxLines is the maximum number of lines of a function body with naked returns
+		maxLines int
+	}
+)
+
+const (
+	// NakedReturnRuleName is the name of the NakedReturnRule
+	NakedReturnRuleName = "naked-return"
+
+	// defaultMaxNakedReturnLines is the default maximum number of lines of a function body with naked returns
+	defaultMaxNakedReturnLines = 5
+)
+
+// NewNakedReturnRule creates a new NakedReturnRule instance with the default limit of 5 lines
+func NewNakedReturnRule() *NakedReturnRule {
+	return &NakedReturnRule{maxLines: defaultMaxNakedReturnLines}
+}
+
+// Name returns the name of the rule
+func (r *NakedReturnRule) Name() string {
+	return NakedReturnRuleName
+}
+
+// Doc returns the documentation of the rule
+func (r *NakedReturnRule) Doc() string {
+	return "reports naked returns in functions longer than max-lines lines (default 5)"
+}
+
+// Severity returns the default severity of the rule
+func (r *NakedReturnRule) Severity() Severity {
+	return SeverityWarning
+}
+
+// Configure sets the max-lines parameter
+func (r *NakedReturnRule) Configure(params Params) error {
+	return params.Limits(0, map[string]*int{"max-lines": &r.maxLines})
+}
+
+// Visit checks every return statement without results against the function it returns from
+func (r *NakedReturnRule) Visit(ctx *Context, node ast.Node) {
+	ret, ok := node.(*ast.ReturnStmt)
+	if !ok || len(ret.Results) > 0 {
+		return
+	}
+
+	name, fnType, body := enclosingFunc(ctx)
+	if fnType == nil || body == nil || countFields(fnType.Results) == 0 || fnType.Results.List[0].Names == nil {
+		return
+	}
+
+	if lines := bodyLines(ctx, body); lines > r.maxLines {
+		ctx.Reportf(ret, "naked return in function %s with %d lines, more than %d", name, lines, r.maxLines)
+	}
+}
+
+// enclosingFunc returns the name, type and body of the innermost function declaration or literal of the current node
+func enclosingFunc(ctx *Context) (string, *ast.FuncType, *ast.BlockStmt) {
+	stack := ctx.Stack()
+	for i := len(stack) - 1; i >= 0; i-- {
+		switch fn := stack[i].(type) {
+		case *ast.FuncDecl:
+			return FuncName(fn), fn.Type, fn.Body
+		case *ast.FuncLit:
+			return fmt.Sprintf("literal (line %d)", ctx.FileSet.Position(fn.Pos()).Line), fn.Type, fn.Body
+		}
+	}
+
+	return "", nil, nil
+}
+
+func init() {
+	RegisterRule(NakedReturnRuleName, func() Rule {
+		return NewNakedReturnRule()
+	})
+}
@@ -0,0 +1,470 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ast
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+type (
+	// NamingRule reports declared identifiers breaking the naming policy:
+	// length limits and patterns per identifier kind, MixedCaps over underscores and initialism casing
+	NamingRule struct {
+		// minLength is the minimum length of the names, keyed by kind
+		minLength map[string]int
+
+		// maxLength is the maximum length of the names keyed by kind, 0 for no limit
+		maxLength map[string]int
+
+		// patterns are the patterns the names must match, keyed by kind
+		patterns map[string]*regexp.Regexp
+
+		// mixedCaps reports names with underscores
+		mixedCaps bool
+
+		// initialisms are the upper case initialisms, e.g. ID or URL
+		initialisms map[string]bool
+
+		// shortScopeLines is the number of lines of a scope in which names shorter than the minimum are allowed
+		shortScopeLines int
+	}
+)
+
+const (
+	// NamingRuleName is the name of the NamingRule
+	NamingRuleName = "naming"
+
+	// NameKindPackage is the kind of package names
+	NameKindPackage = "package"
+	// NameKindType is the kind of type names
+	NameKindType = "type"
+	// NameKindFunc is the kind of function, method and interface method names
+	NameKindFunc = "func"
+	// NameKindVar is the kind of variable, parameter and result names
+	NameKindVar = "var"
+	// NameKindConst is the kind of constant names
+	NameKindConst = "const"
+	// NameKindReceiver is the kind of receiver names
+	NameKindReceiver = "receiver"
+	// NameKindLoopVar is the kind of variables declared by for and range statements
+	NameKindLoopVar = "loopvar"
+
+	// defaultShortScopeLines is the default number of lines of a scope in which short names are allowed
+	defaultShortScopeLines = 5
+)
+
+var (
+	// nameKinds are the kinds of identifiers checked by the NamingRule
+	nameKinds = []string{
+		NameKindPackage, NameKindType, NameKindFunc, NameKindVar, NameKindConst, NameKindReceiver, NameKindLoopVar,
+	}
+
+	defaultMinNameLength = map[string]int{
+		NameKindPackage:  2,
+		NameKindType:     2,
+		NameKindFunc:     2,
+		NameKindVar:      1,
+		NameKindConst:    2,
+		NameKindReceiver: 1,
+		NameKindLoopVar:  1,
+	}
+
+	defaultMaxNameLength = map[string]int{
+		NameKindPackage:  20,
+		NameKindType:     40,
+		NameKindFunc:     40,
+		NameKindVar:      30,
+		NameKindConst:    40,
+		NameKindReceiver: 4,
+		NameKindLoopVar:  20,
+	}
+
+	defaultNamePatterns = map[string]string{
+		NameKindPackage: `^[a-z][a-z0-9]*$`,
+	}
+
+	// defaultInitialisms are the initialisms of the go lint tools
+	defaultInitialisms = []string{
+		"ACL", "API", "ASCII", "CPU", "CSS", "DNS", "EOF", "GUID", "HTML", "HTTP", "HTTPS", "ID", "IP", "JSON",
+		"LHS", "QPS", "RAM", "RHS", "RPC", "SLA", "SMTP", "SQL", "SSH", "TCP", "TLS", "TTL", "UDP", "UI", "UID",
+		"UUID", "URI", "URL", "UTF8", "VM", "XML", "XMPP", "XSRF", "XSS",
+	}
+
+	// testFuncPrefixes are the prefixes of test functions, their names may use underscores
+	testFuncPrefixes = []string{"Test", "Benchmark", "Example", "Fuzz"}
+)
+
+// NewNamingRule creates a new NamingRule instance with the default policy
+func NewNamingRule() *NamingRule {
+	r := &NamingRule{
+		minLength:       make(map[string]int, len(nameKinds)),
+		maxLength:       make(map[string]int, len(nameKinds)),
+		patterns:        make(map[string]*regexp.Regexp, len(nameKinds)),
+		mixedCaps:       true,
+		initialisms:     make(map[string]bool, len(defaultInitialisms)),
+		shortScopeLines: defaultShortScopeLines,
+	}
+	for _, kind := range nameKinds {
+		r.minLength[kind] = defaultMinNameLength[kind]
+		r.maxLength[kind] = defaultMaxNameLength[kind]
+		if pattern, exists := defaultNamePatterns[kind]; exists {
+			r.patterns[kind] = regexp.MustCompile(pattern)
+		}
+	}
+	for _, initialism := range defaultInitialisms {
+		r.initialisms[initialism] = true
+	}
+
+	return r
+}
+
+// Name returns the name of the rule
+func (r *NamingRule) Name() string {
+	return NamingRuleName
+}
+
+// Doc returns the documentation of the rule
+func (r *NamingRule) Doc() string {
+	return "reports declared names breaking the naming policy: length and pattern per kind, MixedCaps and initialisms"
+}
+
+// Severity returns the default severity of the rule
+func (r *NamingRule) Severity() Severity {
+	return SeverityWarning
+}
+
+// Configure sets the parameters of the policy, where kind is one of
+// package, type, func, var, const, receiver and loopvar:
+//   - min-length.<kind> and max-length.<kind>, a max-length of 0 is no limit
+//   - pattern.<kind>, a regular expression the names must match, empty for none
+//   - mixed-caps, whether names with underscores are reported
+//   - initialisms, the list of upper case initialisms
+//   - short-scope-lines, names in scopes of at most this many lines may be shorter than min-length
+func (r *NamingRule) Configure(params Params) error {
+	known := []string{"mixed-caps", "initialisms", "short-scope-lines"}
+	for _, kind := range nameKinds {
+		known = append(known, "min-length."+kind, "max-length."+kind, "pattern."+kind)
+	}
+	if err := params.Check(known...); err != nil {
+		return err
+	}
+
+	for _, kind := range nameKinds {
+		minLength, err := params.Int("min-length."+kind, r.minLength[kind])
+		if err != nil {
+			return err
+		}
+		maxLength, err := params.Int("max-length."+kind, r.maxLength[kind])
+		if err != nil {
+			return err
+		}
+		if minLength < 0 || maxLength < 0 || maxLength > 0 && maxLength < minLength {
+			return fmt.Errorf("invalid length range %d-%d for %s names", minLength, maxLength, kind)
+		}
+		r.minLength[kind], r.maxLength[kind] = minLength, maxLength
+
+		pattern, err := params.String("pattern."+kind, "")
+		if err != nil {
+			return err
+		}
+		if _, exists := params["pattern."+kind]; !exists {
+			continue
+		}
+		if pattern == "" {
+			delete(r.patterns, kind)
+			continue
+		}
+		r.patterns[kind], err = regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern for %s names: %w", kind, err)
+		}
+	}
+
+	var err error
+	if r.mixedCaps, err = params.Bool("mixed-caps", r.mixedCaps); err != nil {
+		return err
+	}
+	if r.shortScopeLines, err = params.Int("short-scope-lines", r.shortScopeLines); err != nil {
+		return err
+	}
+
+	if _, exists := params["initialisms"]; exists {
+		initialisms, err := params.Strings("initialisms", nil)
+		if err != nil {
+			return err
+		}
+		r.initialisms = make(map[string]bool, len(initialisms))
+		for _, initialism := range initialisms {
+			r.initialisms[strings.ToUpper(initialism)] = true
+		}
+	}
+
+	return nil
+}
+
+// Visit checks the names declared by the node
+func (r *NamingRule) Visit(ctx *Context, node ast.Node) {
+	switch n := node.(type) {
+	case *ast.File:
+		r.checkPackage(ctx, n.Name)
+	case *ast.GenDecl:
+		scope := r.enclosingScope(ctx)
+		for _, spec := range n.Specs {
+			switch s := spec.(type) {
+			case *ast.TypeSpec:
+				r.check(ctx, NameKindType, s.Name, scope)
+			case *ast.ValueSpec:
+				kind := NameKindVar
+				if n.Tok == token.CONST {
+					kind = NameKindConst
+				}
+				for _, name := range s.Names {
+					r.check(ctx, kind, name, scope)
+				}
+			}
+		}
+	case *ast.FuncDecl:
+		r.check(ctx, NameKindFunc, n.Name, nil)
+		if n.Recv != nil {
+			r.checkFields(ctx, NameKindReceiver, n.Recv, nil)
+		}
+		r.checkSignature(ctx, n.Type, n)
+	case *ast.FuncLit:
+		r.checkSignature(ctx, n.Type, n)
+	case *ast.InterfaceType:
+		for _, method := range n.Methods.List {
+			if _, ok := method.Type.(*ast.FuncType); ok {
+				for _, name := range method.Names {
+					r.check(ctx, NameKindFunc, name, nil)
+				}
+			}
+		}
+	case *ast.AssignStmt:
+		if n.Tok != token.DEFINE {
+			return
+		}
+		kind, scope := NameKindVar, r.enclosingScope(ctx)
+		if parent, ok := ctx.Parent().(*ast.ForStmt); ok && parent.Init == n {
+			kind = NameKindLoopVar
+		}
+		for _, lhs := range n.Lhs {
+			// := redeclares the names already declared in the scope
+			if ident, ok := lhs.(*ast.Ident); ok && ident.Obj != nil && ident.Obj.Decl == n {
+				r.check(ctx, kind, ident, scope)
+			}
+		}
+	case *ast.RangeStmt:
+		if n.Tok != token.DEFINE {
+			return
+		}
+		for _, expr := range []ast.Expr{n.Key, n.Value} {
+			if ident, ok := expr.(*ast.Ident); ok {
+				r.check(ctx, NameKindLoopVar, ident, n)
+			}
+		}
+	}
+}
+
+// checkPackage checks the package name, the _test suffix of external test packages is not part of the name
+func (r *NamingRule) checkPackage(ctx *Context, ident *ast.Ident) {
+	if isTestFile(ctx) && strings.HasSuffix(ident.Name, "_test") {
+		ident = &ast.Ident{NamePos: ident.NamePos, Name: strings.TrimSuffix(ident.Name, "_test")}
+	}
+
+	r.check(ctx, NameKindPackage, ident, nil)
+}
+
+// checkSignature checks the parameter and result names of a function, their scope is the function
+func (r *NamingRule) checkSignature(ctx *Context, fn *ast.FuncType, scope ast.Node) {
+	r.checkFields(ctx, NameKindVar, fn.Params, scope)
+	r.checkFields(ctx, NameKindVar, fn.Results, scope)
+}
+
+func (r *NamingRule) checkFields(ctx *Context, kind string, fields *ast.FieldList, scope ast.Node) {
+	if fields == nil {
+		return
+	}
+
+	for _, field := range fields.List {
+		for _, name := range field.Names {
+			r.check(ctx, kind, name, scope)
+		}
+	}
+}
+
+// check checks the declared name, scope is the node the name is visible in, nil for package level names
+func (r *NamingRule) check(ctx *Context, kind string, ident *ast.Ident, scope ast.Node) {
+	name := ident.Name
+	if name == "_" || name == "" {
+		return
+	}
+
+	length := len([]rune(name))
+	if min := r.minLength[kind]; length < min && !r.inShortScope(ctx, scope) {
+		ctx.Reportf(ident, "%s name %s is shorter than %d characters", kindLabel(kind), name, min)
+	}
+	if max := r.maxLength[kind]; max > 0 && length > max {
+		ctx.Reportf(ident, "%s name %s is longer than %d characters", kindLabel(kind), name, max)
+	}
+
+	if pattern, exists := r.patterns[kind]; exists && !pattern.MatchString(name) {
+		ctx.Reportf(ident, "%s name %s does not match %s", kindLabel(kind), name, pattern)
+		// the pattern decides the casing, e.g. of package names
+		return
+	}
+
+	if r.mixedCaps && strings.Contains(strings.Trim(name, "_"), "_") && !isTestFunc(ctx, kind, name) {
+		ctx.Reportf(ident, "%s name %s uses underscores, use MixedCaps: %s", kindLabel(kind), name, r.mixedCapsName(name))
+		return
+	}
+
+	if fixed := r.initialismName(name); fixed != name {
+		ctx.Reportf(ident, "%s name %s should be %s", kindLabel(kind), name, fixed)
+	}
+}
+
+// inShortScope reports whether the scope spans at most shortScopeLines lines
+func (r *NamingRule) inShortScope(ctx *Context, scope ast.Node) bool {
+	if scope == nil || r.shortScopeLines <= 0 {
+		return false
+	}
+
+	start, end := ctx.FileSet.Position(scope.Pos()), ctx.FileSet.Position(scope.End())
+	return end.Line-start.Line+1 <= r.shortScopeLines
+}
+
+// enclosingScope returns the innermost block or statement the names declared by the current node are visible in,
+// nil at package level
+func (r *NamingRule) enclosingScope(ctx *Context) ast.Node {
+	stack := ctx.Stack()
+	for i := len(stack) - 2; i >= 0; i-- {
+		switch stack[i].(type) {
+		case *ast.BlockStmt, *ast.CaseClause, *ast.CommClause,
+			*ast.IfStmt, *ast.ForStmt, *ast.SwitchStmt, *ast.TypeSwitchStmt:
+			return stack[i]
+		}
+	}
+
+	return nil
+}
+
+// mixedCapsName returns the name without underscores, keeping the case of its first letter
+func (r *NamingRule) mixedCapsName(name string) string {
+	parts := strings.Split(name, "_")
+	upperFirst := unicode.IsUpper([]rune(name)[0])
+	allUpper := strings.ToUpper(name) == name
+
+	var b strings.Builder
+	for _, part := range parts {
+		if part == "" {
+			continue
+		}
+		if allUpper && !r.initialisms[part] {
+			// MAX_VALUE becomes MaxValue
+			part = strings.ToLower(part)
+		}
+		runes := []rune(part)
+		if b.Len() == 0 && !upperFirst {
+			runes[0] = unicode.ToLower(runes[0])
+		} else {
+			runes[0] = unicode.ToUpper(runes[0])
+		}
+		b.WriteString(string(runes))
+	}
+
+	return r.initialismName(b.String())
+}
+
+// initialismName returns the name with the initialisms in consistent case, e.g. userID for userId
+func (r *NamingRule) initialismName(name string) string {
+	words := splitWords(name)
+	for i, word := range words {
+		upper := strings.ToUpper(word)
+		if !r.initialisms[upper] {
+			continue
+		}
+		switch {
+		case i == 0 && word == strings.ToLower(word):
+			// an unexported name starts with the initialism in lower case
+		case i == 0 && unicode.IsLower([]rune(word)[0]):
+			words[i] = strings.ToLower(word)
+		default:
+			words[i] = upper
+		}
+	}
+
+	return strings.Join(words, "")
+}
+
+// splitWords splits a MixedCaps name into words, e.g. HTTPServerId into HTTP, Server and Id,
+// digits and underscores stay with the preceding word
+func splitWords(name string) []string {
+	runes := []rune(name)
+	var words []string
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		prev, cur := runes[i-1], runes[i]
+		boundary := unicode.IsUpper(cur) && (unicode.IsLower(prev) || unicode.IsDigit(prev)) ||
+			// the last upper case letter of an initialism starts the next word, e.g. the S of HTTPServer
+			unicode.IsUpper(prev) && unicode.IsUpper(cur) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+		if boundary {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+
+	return append(words, string(runes[start:]))
+}
+
+// isTestFunc reports whether the name is a test function of a _test.go file, e.g. TestEngine_Run
+func isTestFunc(ctx *Context, kind, name string) bool {
+	if kind != NameKindFunc || !isTestFile(ctx) {
+		return false
+	}
+
+	for _, prefix := range testFuncPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isTestFile(ctx *Context) bool {
+	return strings.HasSuffix(ctx.FileSet.Position(ctx.File.Pos()).Filename, "_test.go")
+}
+
+func kindLabel(kind string) string {
+	if kind == NameKindLoopVar {
+		return "loop var"
+	}
+
+	return kind
+}
+
+func init() {
+	RegisterRule(NamingRuleName, func() Rule {
+		return NewNamingRule()
+	})
+}
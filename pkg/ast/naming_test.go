@@ -0,0 +1,134 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ast_test
+
+import (
+	"testing"
+
+	testAssert "github.com/stretchr/testify/assert"
+
+	"github.com/LokiWager/analysis-demo/pkg/ast"
+)
+
+func runNamingRule(t *testing.T, file, src string, params ast.Params) []string {
+	rule := ast.NewNamingRule()
+	if err := rule.Configure(params); err != nil {
+		t.Fatalf("configure failed: %v", err)
+	}
+
+	e, err := ast.NewEngine(file, src)
+	if err != nil {
+		t.Fatalf("new engine failed: %v", err)
+	}
+
+	var messages []string
+	for _, finding := range e.Run(rule) {
+		messages = append(messages, finding.Message)
+	}
+	return messages
+}
+
+// TestNamingRule tests the naming policy with configured parameters
+func TestNamingRule(t *testing.T) {
+	src := `
+package service
+
+func process(items []string, n int) int {
+	for i := range items {
+		c := items[i]
+		_ = c
+	}
+
+	m := n * 2
+	if m > 10 {
+		return m
+	}
+	return n
+}
+`
+
+	t.Run("Short names in short scopes", func(t *testing.T) {
+		assert := testAssert.New(t)
+		messages := runNamingRule(t, "service.go", src, ast.Params{
+			"min-length.var":     "2",
+			"min-length.loopvar": "2",
+		})
+
+		// i and c are declared in the 4 lines of the range statement
+		assert.Equal([]string{
+			"var name n is shorter than 2 characters",
+			"var name m is shorter than 2 characters",
+		}, messages)
+	})
+
+	t.Run("Short scopes disabled", func(t *testing.T) {
+		assert := testAssert.New(t)
+		messages := runNamingRule(t, "service.go", src, ast.Params{
+			"min-length.var":     2,
+			"min-length.loopvar": 2,
+			"short-scope-lines":  0,
+		})
+		assert.Len(messages, 4)
+	})
+
+	t.Run("Patterns and initialisms", func(t *testing.T) {
+		assert := testAssert.New(t)
+		messages := runNamingRule(t, "service.go", `
+package service
+
+type GrpcClient struct{}
+
+type grpcServer struct{}
+
+func (c *GrpcClient) GetHttpUrl() {}
+
+func Process_Item() {}
+`, ast.Params{
+			"pattern.func": "^[A-Z]",
+			"initialisms":  "GRPC,URL",
+			"mixed-caps":   "false",
+		})
+
+		assert.Equal([]string{
+			"type name GrpcClient should be GRPCClient",
+			"func name GetHttpUrl should be GetHttpURL",
+		}, messages)
+	})
+
+	t.Run("Test files", func(t *testing.T) {
+		assert := testAssert.New(t)
+		messages := runNamingRule(t, "service_test.go", `
+package service_test
+
+func TestProcess_Empty() {}
+
+func helper_func() {}
+`, nil)
+
+		assert.Equal([]string{
+			"func name helper_func uses underscores, use MixedCaps: helperFunc",
+		}, messages)
+	})
+
+	t.Run("Invalid parameters", func(t *testing.T) {
+		assert := testAssert.New(t)
+		assert.Error(ast.NewNamingRule().Configure(ast.Params{"min-length.field": 2}))
+		assert.Error(ast.NewNamingRule().Configure(ast.Params{"min-length.var": 5, "max-length.var": 3}))
+		assert.Error(ast.NewNamingRule().Configure(ast.Params{"pattern.type": "["}))
+	})
+}
@@ -0,0 +1,240 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ast
+
+import (
+	"fmt"
+	"go/ast"
+	"strings"
+)
+
+type (
+	// NestingRule reports control flow (if, for, range, switch, type switch, select) nested more than
+	// max-depth levels in a function, function literals start again at level 0
+	NestingRule struct {
+		// maxDepth is the maximum allowed nesting depth of control flow
+		maxDepth int
+
+		// scope is the nesting of the function being visited
+		scope *nestingScope
+
+		// outer are the scopes of the functions enclosing the function literal being visited
+		outer []*nestingScope
+	}
+
+	// nestingScope is the nesting of control flow in one function
+	nestingScope struct {
+		// name describes the function, e.g. "func main" or "func literal (line 12)"
+		name string
+
+		// frames are the control flow statements enclosing the current node, from the outermost one
+		frames []*nestingFrame
+	}
+
+	// nestingFrame is a control flow statement enclosing the current node
+	nestingFrame struct {
+		node ast.Node
+
+		// clause is the case or comm clause of a switch or select being visited, nil otherwise
+		clause ast.Node
+
+		// reported is set when a statement nested in this one was reported
+		reported bool
+	}
+)
+
+const (
+	// NestingRuleName is the name of the NestingRule
+	NestingRuleName = "nesting-depth"
+
+	// defaultMaxNestingDepth is the default maximum allowed nesting depth of control flow
+	defaultMaxNestingDepth = 4
+)
+
+// NewNestingRule creates a new NestingRule instance with the default limit of 4 levels
+func NewNestingRule() *NestingRule {
+	return &NestingRule{maxDepth: defaultMaxNestingDepth}
+}
+
+// Name returns the name of the rule
+func (r *NestingRule) Name() string {
+	return NestingRuleName
+}
+
+// Doc returns the documentation of the rule
+func (r *NestingRule) Doc() string {
+	return "reports control flow (if, for, range, switch, select) nested more than max-depth (default 4) levels in a function"
+}
+
+// Severity returns the default severity of the rule
+func (r *NestingRule) Severity() Severity {
+	return SeverityWarning
+}
+
+// Configure sets the max-depth parameter
+func (r *NestingRule) Configure(params Params) error {
+	return params.Limits(1, map[string]*int{"max-depth": &r.maxDepth})
+}
+
+// Visit enters a new scope for every function and a new level for every control flow statement
+func (r *NestingRule) Visit(ctx *Context, node ast.Node) {
+	switch n := node.(type) {
+	case *ast.FuncDecl:
+		r.enterScope("func " + n.Name.Name)
+	case *ast.FuncLit:
+		r.enterScope(fmt.Sprintf("func literal (line %d)", ctx.FileSet.Position(n.Pos()).Line))
+	case *ast.CaseClause, *ast.CommClause:
+		// a clause belongs to the level of its switch or select, the path shows which one is taken
+		if frame := r.top(); frame != nil {
+			frame.clause = node
+		}
+	default:
+		if r.scope == nil || !isNestingLevel(ctx, node) {
+			return
+		}
+		r.scope.frames = append(r.scope.frames, &nestingFrame{node: node})
+	}
+}
+
+// Leave reports the innermost statements crossing the limit when leaving them and restores the enclosing scope
+func (r *NestingRule) Leave(ctx *Context, node ast.Node) {
+	switch node.(type) {
+	case *ast.FuncDecl, *ast.FuncLit:
+		r.leaveScope()
+	case *ast.CaseClause, *ast.CommClause:
+		if frame := r.top(); frame != nil {
+			frame.clause = nil
+		}
+	default:
+		if r.scope == nil || !isNestingLevel(ctx, node) {
+			return
+		}
+
+		frame := r.top()
+		depth := len(r.scope.frames)
+		// report only the innermost statement of a chain, its path covers the enclosing ones
+		if depth > r.maxDepth && !frame.reported {
+			ctx.Reportf(node, "control flow nested %d levels deep, more than %d: %s", depth, r.maxDepth, r.path(ctx))
+		}
+
+		r.scope.frames = r.scope.frames[:depth-1]
+		if depth > r.maxDepth {
+			if parent := r.top(); parent != nil {
+				parent.reported = true
+			}
+		}
+	}
+}
+
+func (r *NestingRule) enterScope(name string) {
+	if r.scope != nil {
+		r.outer = append(r.outer, r.scope)
+	}
+	r.scope = &nestingScope{name: name}
+}
+
+func (r *NestingRule) leaveScope() {
+	r.scope = nil
+	if len(r.outer) > 0 {
+		r.scope = r.outer[len(r.outer)-1]
+		r.outer = r.outer[:len(r.outer)-1]
+	}
+}
+
+// top returns the innermost control flow statement of the current scope, nil if there is none
+func (r *NestingRule) top() *nestingFrame {
+	if r.scope == nil || len(r.scope.frames) == 0 {
+		return nil
+	}
+
+	return r.scope.frames[len(r.scope.frames)-1]
+}
+
+// path describes the nesting of the current statement, e.g.
+// "func main > for (line 4) > switch (line 5) > case (line 6) > if (line 7)"
+func (r *NestingRule) path(ctx *Context) string {
+	parts := []string{r.scope.name}
+	for _, frame := range r.scope.frames {
+		parts = append(parts, fmt.Sprintf("%s (line %d)", nestingLabel(frame.node), ctx.FileSet.Position(frame.node.Pos()).Line))
+		if frame.clause != nil {
+			parts = append(parts, fmt.Sprintf("%s (line %d)", clauseLabel(frame.clause), ctx.FileSet.Position(frame.clause.Pos()).Line))
+		}
+	}
+
+	return strings.Join(parts, " > ")
+}
+
+// isNestingLevel reports whether the node is a control flow statement adding a nesting level,
+// an else if continues the level of its if
+func isNestingLevel(ctx *Context, node ast.Node) bool {
+	if ifStmt, ok := node.(*ast.IfStmt); ok {
+		parent, ok := ctx.Parent().(*ast.IfStmt)
+		return !ok || parent.Else != ifStmt
+	}
+
+	return isControlFlow(node)
+}
+
+func isControlFlow(node ast.Node) bool {
+	switch node.(type) {
+	case *ast.IfStmt, *ast.ForStmt, *ast.RangeStmt, *ast.SwitchStmt, *ast.TypeSwitchStmt, *ast.SelectStmt:
+		return true
+	}
+
+	return false
+}
+
+func nestingLabel(node ast.Node) string {
+	switch node.(type) {
+	case *ast.IfStmt:
+		return "if"
+	case *ast.ForStmt:
+		return "for"
+	case *ast.RangeStmt:
+		return "range"
+	case *ast.SwitchStmt:
+		return "switch"
+	case *ast.TypeSwitchStmt:
+		return "type switch"
+	case *ast.SelectStmt:
+		return "select"
+	}
+
+	return "statement"
+}
+
+func clauseLabel(node ast.Node) string {
+	switch clause := node.(type) {
+	case *ast.CaseClause:
+		if clause.List == nil {
+			return "default"
+		}
+	case *ast.CommClause:
+		if clause.Comm == nil {
+			return "default"
+		}
+	}
+
+	return "case"
+}
+
+func init() {
+	RegisterRule(NestingRuleName, func() Rule {
+		return NewNestingRule()
+	})
+}
@@ -0,0 +1,241 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ast
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type (
+	// Params are the parameters of a rule keyed by parameter name,
+	// values come from the command line as strings or from configuration files as decoded values
+	Params map[string]any
+
+	// ConfigurableRule is implemented by rules taking parameters
+	ConfigurableRule interface {
+		Rule
+
+		// Configure applies the parameters to the rule, it fails on unknown parameters or invalid values
+		Configure(params Params) error
+	}
+)
+
+// ConfigureRules applies the parameters to the rules, keyed by rule name
+// parameters of rules that are not in the list are ignored, so disabling a rule keeps its parameters valid,
+// but parameters of unknown rules or rules taking no parameter are errors
+func ConfigureRules(rules []Rule, params map[string]Params) error {
+	byName := make(map[string]Rule, len(rules))
+	for _, rule := range rules {
+		if wrapped, ok := rule.(*severityRule); ok {
+			rule = wrapped.Rule
+		}
+		byName[rule.Name()] = rule
+	}
+
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if _, exists := RuleRegistry[name]; !exists {
+			return fmt.Errorf("rule %s not found", name)
+		}
+
+		rule, exists := byName[name]
+		if !exists {
+			continue
+		}
+
+		configurable, ok := rule.(ConfigurableRule)
+		if !ok {
+			return fmt.Errorf("rule %s takes no parameters", name)
+		}
+		if err := configurable.Configure(params[name]); err != nil {
+			return fmt.Errorf("rule %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// Check returns an error if a parameter is not one of the known ones
+func (p Params) Check(known ...string) error {
+	for _, key := range p.keys() {
+		found := false
+		for _, name := range known {
+			if key == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("unknown parameter %s, expected one of %s", key, strings.Join(known, ", "))
+		}
+	}
+
+	return nil
+}
+
+// Int returns the integer parameter, or def if it is not set
+func (p Params) Int(key string, def int) (int, error) {
+	value, exists := p[key]
+	if !exists {
+		return def, nil
+	}
+
+	switch v := value.(type) {
+	case int:
+		return v, nil
+	case int64:
+		return int(v), nil
+	case uint64:
+		return int(v), nil
+	case float64:
+		if v != math.Trunc(v) {
+			return 0, fmt.Errorf("parameter %s: %v is not an integer", key, v)
+		}
+		return int(v), nil
+	case string:
+		i, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil {
+			return 0, fmt.Errorf("parameter %s: %q is not an integer", key, v)
+		}
+		return i, nil
+	}
+
+	return 0, fmt.Errorf("parameter %s: %v is not an integer", key, value)
+}
+
+// Limits sets the integer limits keyed by parameter name from the parameters, the only known ones,
+// the current values are the defaults, a limit less than minimum is an error and no limit is set then
+func (p Params) Limits(minimum int, limits map[string]*int) error {
+	keys := make([]string, 0, len(limits))
+	for key := range limits {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	if err := p.Check(keys...); err != nil {
+		return err
+	}
+
+	values := make(map[string]int, len(limits))
+	for _, key := range keys {
+		value, err := p.Int(key, *limits[key])
+		if err != nil {
+			return err
+		}
+		if value < minimum {
+			return fmt.Errorf("parameter %s must be at least %d, got %d", key, minimum, value)
+		}
+		values[key] = value
+	}
+	for key, value := range values {
+		*limits[key] = value
+	}
+
+	return nil
+}
+
+// Bool returns the boolean parameter, or def if it is not set
+func (p Params) Bool(key string, def bool) (bool, error) {
+	value, exists := p[key]
+	if !exists {
+		return def, nil
+	}
+
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case string:
+		b, err := strconv.ParseBool(strings.TrimSpace(v))
+		if err != nil {
+			return false, fmt.Errorf("parameter %s: %q is not a boolean", key, v)
+		}
+		return b, nil
+	}
+
+	return false, fmt.Errorf("parameter %s: %v is not a boolean", key, value)
+}
+
+// String returns the string parameter, or def if it is not set
+func (p Params) String(key string, def string) (string, error) {
+	value, exists := p[key]
+	if !exists {
+		return def, nil
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case int, int64, uint64, float64, bool:
+		return fmt.Sprint(v), nil
+	}
+
+	return "", fmt.Errorf("parameter %s: %v is not a string", key, value)
+}
+
+// Strings returns the list parameter, or def if it is not set
+// a string value is split on commas, so lists can be given on the command line
+func (p Params) Strings(key string, def []string) ([]string, error) {
+	value, exists := p[key]
+	if !exists {
+		return def, nil
+	}
+
+	switch v := value.(type) {
+	case string:
+		if strings.TrimSpace(v) == "" {
+			return nil, nil
+		}
+		parts := strings.Split(v, ",")
+		for i, part := range parts {
+			parts[i] = strings.TrimSpace(part)
+		}
+		return parts, nil
+	case []string:
+		return v, nil
+	case []any:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			switch item.(type) {
+			case map[string]any, []any:
+				return nil, fmt.Errorf("parameter %s: %v is not a list of strings", key, value)
+			}
+			list = append(list, fmt.Sprint(item))
+		}
+		return list, nil
+	}
+
+	return nil, fmt.Errorf("parameter %s: %v is not a list", key, value)
+}
+
+func (p Params) keys() []string {
+	keys := make([]string, 0, len(p))
+	for key := range p {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,425 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ast
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"go/types"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+type (
+	// Pattern is a Go expression or statement list with metavariables matched against the syntax tree:
+	//   - $x matches any expression, statement or name, a metavariable used twice matches the same code twice
+	//   - $*x matches any number of elements of a list, e.g. the arguments of a call or the statements of a block
+	//   - $_ and $*_ match without binding
+	//
+	// metavariables may be constrained to a type, they then only match expressions of that type
+	Pattern struct {
+		// Source is the text of the pattern
+		Source string
+
+		// Types are the type constraints of the metavariables keyed by name without $,
+		// e.g. *sync.Map or *github.com/org/repo/pkg.Type
+		Types map[string]string
+
+		// expr is the pattern if it is an expression
+		expr ast.Expr
+
+		// stmts is the pattern if it is a list of statements
+		stmts []ast.Stmt
+	}
+
+	// Bindings are the code matched by the metavariables of a pattern keyed by name without $,
+	// values are an ast.Node or, for list metavariables, a []ast.Node
+	Bindings map[string]any
+
+	// matcher matches the nodes of a pattern against the nodes of a file
+	matcher struct {
+		// pattern is the pattern being matched
+		pattern *Pattern
+
+		// info is the type information of the file, nil if the pattern has no type constraint
+		info *types.Info
+
+		// bindings are the metavariables bound so far
+		bindings Bindings
+	}
+)
+
+const (
+	// metavarPrefix replaces the $ of a metavariable so the pattern parses as Go
+	metavarPrefix = "_pattern_"
+
+	// listMetavarPrefix replaces the $* of a list metavariable so the pattern parses as Go
+	listMetavarPrefix = "_patterns_"
+
+	// wildcard is the name of the metavariable matching without binding
+	wildcard = "_"
+)
+
+var (
+	// metavarPattern matches the metavariables of the source of a pattern
+	metavarPattern = regexp.MustCompile(`\$(\*?)([A-Za-z_][A-Za-z0-9_]*)`)
+
+	// ignoredFieldTypes are the fields of the syntax tree not compared by the matcher
+	ignoredFieldTypes = map[reflect.Type]bool{
+		reflect.TypeOf(token.NoPos):              true,
+		reflect.TypeOf((*ast.Object)(nil)):       true,
+		reflect.TypeOf((*ast.Scope)(nil)):        true,
+		reflect.TypeOf((*ast.CommentGroup)(nil)): true,
+	}
+)
+
+// ParsePattern parses the source of a pattern, an expression or a list of statements,
+// the type constraints are keyed by metavariable name without $
+func ParsePattern(source string, constraints map[string]string) (*Pattern, error) {
+	p := &Pattern{Source: source, Types: constraints}
+	for name := range constraints {
+		if !strings.Contains(source, "$"+name) || name == wildcard {
+			return nil, fmt.Errorf("pattern %s has no metavariable $%s to constrain", source, name)
+		}
+	}
+
+	src := metavarPattern.ReplaceAllStringFunc(source, func(metavar string) string {
+		match := metavarPattern.FindStringSubmatch(metavar)
+		if match[1] != "" {
+			return listMetavarPrefix + match[2]
+		}
+		return metavarPrefix + match[2]
+	})
+
+	if expr, err := parser.ParseExpr(src); err == nil {
+		p.expr = expr
+		return p, nil
+	}
+
+	file, err := parser.ParseFile(token.NewFileSet(), "", "package p\nfunc _() {\n"+src+"\n}", 0)
+	if err != nil {
+		return nil, fmt.Errorf("pattern %s is neither an expression nor a list of statements: %w", source, err)
+	}
+	p.stmts = file.Decls[0].(*ast.FuncDecl).Body.List
+	if len(p.stmts) == 0 {
+		return nil, fmt.Errorf("pattern %s is empty", source)
+	}
+
+	return p, nil
+}
+
+// RequiresTypes reports whether a metavariable of the pattern has a type constraint
+func (p *Pattern) RequiresTypes() bool {
+	return len(p.Types) > 0
+}
+
+// Match matches a pattern of an expression or a statement against the node, patterns of several statements
+// are matched by MatchStmts, info is the type information of the file and may be nil without type constraints
+func (p *Pattern) Match(node ast.Node, info *types.Info) (Bindings, bool) {
+	m := &matcher{pattern: p, info: info, bindings: Bindings{}}
+	if p.expr != nil {
+		return m.bindings, m.matchNode(p.expr, node)
+	}
+	if len(p.stmts) == 1 {
+		return m.bindings, m.matchNode(p.stmts[0], node)
+	}
+
+	return nil, false
+}
+
+// MatchStmts matches a pattern of several statements against the statements of a list from start,
+// it returns the number of statements matched, 0 if the pattern does not match there
+func (p *Pattern) MatchStmts(stmts []ast.Stmt, info *types.Info) (Bindings, int) {
+	if len(p.stmts) < 2 {
+		return nil, 0
+	}
+
+	// the shortest match is the one reported
+	for end := 1; end <= len(stmts); end++ {
+		m := &matcher{pattern: p, info: info, bindings: Bindings{}}
+		if m.matchList(reflect.ValueOf(p.stmts), reflect.ValueOf(stmts[:end])) {
+			return m.bindings, end
+		}
+	}
+
+	return nil, 0
+}
+
+// Expand replaces the $x metavariables of the text by the code they are bound to
+func (b Bindings) Expand(fileSet *token.FileSet, text string) string {
+	return metavarPattern.ReplaceAllStringFunc(text, func(metavar string) string {
+		match := metavarPattern.FindStringSubmatch(metavar)
+		switch bound := b[match[2]].(type) {
+		case ast.Node:
+			return nodeText(fileSet, bound)
+		case []ast.Node:
+			texts := make([]string, 0, len(bound))
+			for _, node := range bound {
+				texts = append(texts, nodeText(fileSet, node))
+			}
+			return strings.Join(texts, ", ")
+		}
+		return metavar
+	})
+}
+
+// Names returns the names of the bound metavariables in order
+func (b Bindings) Names() []string {
+	names := make([]string, 0, len(b))
+	for name := range b {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// matchNode matches the pattern node against the node
+func (m *matcher) matchNode(pattern, node ast.Node) bool {
+	if name, ok := metavarName(pattern, metavarPrefix); ok {
+		return m.bind(name, node)
+	}
+	if isNilNode(pattern) || isNilNode(node) {
+		return isNilNode(pattern) && isNilNode(node)
+	}
+
+	pv, nv := reflect.ValueOf(pattern), reflect.ValueOf(node)
+	if pv.Type() != nv.Type() {
+		return false
+	}
+
+	return m.matchValue(pv.Elem(), nv.Elem())
+}
+
+// matchValue matches the fields of the pattern against the fields of the node, positions,
+// comments and objects are ignored
+func (m *matcher) matchValue(pattern, node reflect.Value) bool {
+	switch pattern.Kind() {
+	case reflect.Struct:
+		for i := 0; i < pattern.NumField(); i++ {
+			if ignoredFieldTypes[pattern.Type().Field(i).Type] {
+				continue
+			}
+			if !m.matchValue(pattern.Field(i), node.Field(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Pointer, reflect.Interface:
+		if pattern.IsNil() || node.IsNil() {
+			return pattern.IsNil() && node.IsNil()
+		}
+		if patternNode, ok := pattern.Interface().(ast.Node); ok {
+			n, ok := node.Interface().(ast.Node)
+			return ok && m.matchNode(patternNode, n)
+		}
+		return m.matchValue(pattern.Elem(), node.Elem())
+	case reflect.Slice:
+		return m.matchList(pattern, node)
+	}
+
+	return pattern.Interface() == node.Interface()
+}
+
+// matchList matches the elements of the pattern list against the elements of the list,
+// a list metavariable matches any number of elements
+func (m *matcher) matchList(pattern, list reflect.Value) bool {
+	if pattern.Len() == 0 {
+		return list.Len() == 0
+	}
+
+	first := pattern.Index(0)
+	if name, ok := listMetavarName(first); ok {
+		for end := 0; end <= list.Len(); end++ {
+			saved := m.save()
+			if m.bindList(name, list.Slice(0, end)) && m.matchList(pattern.Slice(1, pattern.Len()), list.Slice(end, list.Len())) {
+				return true
+			}
+			m.bindings = saved
+		}
+		return false
+	}
+
+	if list.Len() == 0 {
+		return false
+	}
+
+	saved := m.save()
+	if m.matchValue(first, list.Index(0)) && m.matchList(pattern.Slice(1, pattern.Len()), list.Slice(1, list.Len())) {
+		return true
+	}
+	m.bindings = saved
+
+	return false
+}
+
+// bind binds the metavariable to the node, a bound metavariable only matches the same code again
+func (m *matcher) bind(name string, node ast.Node) bool {
+	if isNilNode(node) || !m.satisfies(name, node) {
+		return false
+	}
+	if name == wildcard {
+		return true
+	}
+
+	if bound, exists := m.bindings[name]; exists {
+		boundNode, ok := bound.(ast.Node)
+		return ok && sameCode(boundNode, node)
+	}
+	m.bindings[name] = node
+
+	return true
+}
+
+// bindList binds the list metavariable to the elements of the list
+func (m *matcher) bindList(name string, list reflect.Value) bool {
+	nodes := make([]ast.Node, 0, list.Len())
+	for i := 0; i < list.Len(); i++ {
+		node, ok := list.Index(i).Interface().(ast.Node)
+		if !ok {
+			return false
+		}
+		nodes = append(nodes, node)
+	}
+	if name == wildcard {
+		return true
+	}
+
+	if bound, exists := m.bindings[name]; exists {
+		boundNodes, ok := bound.([]ast.Node)
+		if !ok || len(boundNodes) != len(nodes) {
+			return false
+		}
+		for i := range nodes {
+			if !sameCode(boundNodes[i], nodes[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	m.bindings[name] = nodes
+
+	return true
+}
+
+// satisfies reports whether the node satisfies the type constraint of the metavariable, if any
+func (m *matcher) satisfies(name string, node ast.Node) bool {
+	constraint, exists := m.pattern.Types[name]
+	if !exists {
+		return true
+	}
+
+	expr, ok := node.(ast.Expr)
+	if !ok || m.info == nil {
+		return false
+	}
+	t := m.info.TypeOf(expr)
+	if t == nil {
+		return false
+	}
+
+	return types.TypeString(t, nil) == constraint ||
+		types.TypeString(t, func(pkg *types.Package) string { return pkg.Name() }) == constraint
+}
+
+// sameCode reports whether the nodes are the same code, whatever their positions
+func sameCode(a, b ast.Node) bool {
+	return (&matcher{pattern: &Pattern{}, bindings: Bindings{}}).matchNode(a, b)
+}
+
+// save returns a copy of the bindings to restore when a list match backtracks
+func (m *matcher) save() Bindings {
+	saved := make(Bindings, len(m.bindings))
+	for name, bound := range m.bindings {
+		saved[name] = bound
+	}
+
+	return saved
+}
+
+// metavarName returns the name of the metavariable the node stands for, statements and fields
+// made of a metavariable stand for it too
+func metavarName(node ast.Node, prefix string) (string, bool) {
+	switch x := node.(type) {
+	case *ast.Ident:
+		if name, found := strings.CutPrefix(x.Name, prefix); found {
+			return name, true
+		}
+	case *ast.ExprStmt:
+		return metavarName(x.X, prefix)
+	case *ast.Field:
+		if len(x.Names) == 0 && x.Tag == nil {
+			return metavarName(x.Type, prefix)
+		}
+	}
+
+	return "", false
+}
+
+// listMetavarName returns the name of the list metavariable the element of a list stands for
+func listMetavarName(element reflect.Value) (string, bool) {
+	if element.Kind() != reflect.Pointer && element.Kind() != reflect.Interface || element.IsNil() {
+		return "", false
+	}
+	node, ok := element.Interface().(ast.Node)
+	if !ok {
+		return "", false
+	}
+
+	return metavarName(node, listMetavarPrefix)
+}
+
+// isNilNode reports whether the node is nil or a typed nil pointer
+func isNilNode(node ast.Node) bool {
+	if node == nil {
+		return true
+	}
+	v := reflect.ValueOf(node)
+
+	return v.Kind() == reflect.Pointer && v.IsNil()
+}
+
+// nodeText returns the source code of the node
+func nodeText(fileSet *token.FileSet, node ast.Node) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fileSet, node); err != nil {
+		return ""
+	}
+
+	return buf.String()
+}
+
+// stmtList returns the statements of a block, a case clause or a communication clause, nil for other nodes
+func stmtList(node ast.Node) []ast.Stmt {
+	switch x := node.(type) {
+	case *ast.BlockStmt:
+		return x.List
+	case *ast.CaseClause:
+		return x.Body
+	case *ast.CommClause:
+		return x.Body
+	}
+
+	return nil
+}
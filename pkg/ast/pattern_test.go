@@ -0,0 +1,141 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ast_test
+
+import (
+	"testing"
+
+	testAssert "github.com/stretchr/testify/assert"
+
+	"github.com/LokiWager/analysis-demo/pkg/ast"
+)
+
+func TestParsePattern(t *testing.T) {
+	assert := testAssert.New(t)
+
+	pattern, err := ast.ParsePattern("$x.State = $v", nil)
+	assert.NoError(err)
+	assert.False(pattern.RequiresTypes())
+
+	pattern, err = ast.ParsePattern("$m.Load($*_)", map[string]string{"m": "sync.Map"})
+	assert.NoError(err)
+	assert.True(pattern.RequiresTypes())
+
+	_, err = ast.ParsePattern("$x +", nil)
+	assert.Error(err)
+	_, err = ast.ParsePattern("$x.Load()", map[string]string{"m": "sync.Map"})
+	assert.Error(err)
+}
+
+// TestPatternRule tests the patterns of the parameters, with and without type constraints
+func TestPatternRule(t *testing.T) {
+	src := `
+package service
+
+import (
+	"fmt"
+	"sync"
+)
+
+type Process struct {
+	State string
+}
+
+var processes sync.Map
+
+func update(pid int, state string) {
+	value, _ := processes.Load(pid)
+	process := value.(*Process)
+	process.State = state
+	state = state
+
+	other := &struct{ State string }{}
+	other.State = state
+	fmt.Println("updated", pid, state)
+}
+`
+
+	t.Run("PatternRule with type constraints", func(t *testing.T) {
+		assert := testAssert.New(t)
+		rules := []ast.Rule{ast.NewPatternRule()}
+		err := ast.ConfigureRules(rules, map[string]ast.Params{ast.PatternRuleName: {
+			"state-store.match":    "$x.State = $v",
+			"state-store.message":  "$x.State is set to $v without the lock of the process",
+			"state-store.severity": "error",
+			"state-store.types.x":  "*service.Process",
+			"load-assert.match":    "$v, _ := $m.Load($_)\n$x := $v.(*Process)",
+			"load-assert.types.m":  "sync.Map",
+		}})
+		assert.NoError(err)
+		assert.True(ast.RequiresTypes(rules[0]))
+
+		fileSet, file, info := typeCheck(t, "service.go", src)
+		e := ast.NewEngineFromFile(fileSet, file)
+		e.SetTypesInfo(info)
+		findings := e.Run(rules...)
+		if assert.Len(findings, 2) {
+			assert.Equal("code matches pattern load-assert", findings[0].Message)
+			assert.Equal(16, findings[0].Position.Line)
+			assert.Equal(ast.SeverityWarning, findings[0].Severity)
+
+			assert.Equal("process.State is set to state without the lock of the process", findings[1].Message)
+			assert.Equal(18, findings[1].Position.Line)
+			assert.Equal(ast.SeverityError, findings[1].Severity)
+		}
+
+		// the rule is skipped without types
+		e, err = ast.NewEngine("service.go", src)
+		assert.NoError(err)
+		assert.Empty(e.Run(rules...))
+	})
+
+	t.Run("PatternRule with repeated and list metavariables", func(t *testing.T) {
+		assert := testAssert.New(t)
+		rules := []ast.Rule{ast.NewPatternRule()}
+		err := ast.ConfigureRules(rules, map[string]ast.Params{ast.PatternRuleName: {
+			"self-assign.match":   "$x = $x",
+			"self-assign.message": "$x is assigned to itself",
+			"println.match":       `fmt.Println("updated", $*args)`,
+			"println.message":     "log the update of $args",
+		}})
+		assert.NoError(err)
+		assert.False(ast.RequiresTypes(rules[0]))
+
+		e, err := ast.NewEngine("service.go", src)
+		assert.NoError(err)
+		var messages []string
+		for _, finding := range e.Run(rules...) {
+			messages = append(messages, finding.Message)
+		}
+		assert.Equal([]string{"state is assigned to itself", "log the update of pid, state"}, messages)
+	})
+
+	t.Run("PatternRule with invalid parameters", func(t *testing.T) {
+		assert := testAssert.New(t)
+		rules := []ast.Rule{ast.NewPatternRule()}
+		for _, params := range []ast.Params{
+			{"match": "$x = $x"},
+			{"self-assign.message": "no pattern"},
+			{"self-assign.match": "$x = $x", "self-assign.level": "error"},
+			{"self-assign.match": "$x = $x", "self-assign.severity": "fatal"},
+			{"self-assign.match": "$x = $x", "self-assign.types.y": "int"},
+		} {
+			assert.Error(ast.ConfigureRules(rules, map[string]ast.Params{ast.PatternRuleName: params}), params)
+		}
+	})
+}
@@ -0,0 +1,198 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ast
+
+import (
+	"fmt"
+	"go/ast"
+	"sort"
+	"strings"
+)
+
+type (
+	// PatternRule reports the code matching the patterns of its parameters, so project-specific rules
+	// are written without Go code, every pattern is declared by parameters under its name:
+	//   - <name>.match is the pattern, see Pattern
+	//   - <name>.message is the message of the findings, $x is replaced by the code bound to $x,
+	//     "code matches pattern <name>" by default
+	//   - <name>.severity is the severity of the findings, the severity of the rule if empty
+	//   - <name>.types.<x> is the type constraint of the metavariable $x
+	PatternRule struct {
+		// patterns are the patterns ordered by name
+		patterns []namedPattern
+	}
+
+	// namedPattern is a pattern declared by the parameters of the rule
+	namedPattern struct {
+		// name is the name of the pattern in the parameters
+		name string
+
+		// pattern is the parsed pattern
+		pattern *Pattern
+
+		// message is the message of the findings, with metavariables to expand
+		message string
+
+		// severity is the severity of the findings, the severity of the rule if empty
+		severity Severity
+	}
+)
+
+const (
+	// PatternRuleName is the name of the PatternRule
+	PatternRuleName = "pattern"
+)
+
+// patternKeys are the parameters of a pattern, the type constraints excepted
+var patternKeys = []string{"match", "message", "severity"}
+
+// NewPatternRule creates a new PatternRule instance without patterns
+func NewPatternRule() *PatternRule {
+	return &PatternRule{}
+}
+
+// Name returns the name of the rule
+func (r *PatternRule) Name() string {
+	return PatternRuleName
+}
+
+// Doc returns the documentation of the rule
+func (r *PatternRule) Doc() string {
+	return "reports the code matching the patterns of the configuration"
+}
+
+// Severity returns the default severity of the rule
+func (r *PatternRule) Severity() Severity {
+	return SeverityWarning
+}
+
+// RequiresTypes reports whether a pattern has a type constraint
+func (r *PatternRule) RequiresTypes() bool {
+	for _, p := range r.patterns {
+		if p.pattern.RequiresTypes() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Configure parses the patterns of the parameters
+func (r *PatternRule) Configure(params Params) error {
+	type patternParams struct {
+		values Params
+		types  map[string]string
+	}
+
+	byName := make(map[string]*patternParams)
+	for key := range params {
+		name, param, found := strings.Cut(key, ".")
+		if !found || name == "" {
+			return fmt.Errorf("unknown parameter %s, want <pattern>.%s or <pattern>.types.<metavariable>",
+				key, strings.Join(patternKeys, "|"))
+		}
+		if byName[name] == nil {
+			byName[name] = &patternParams{values: Params{}, types: map[string]string{}}
+		}
+
+		if metavar, isType := strings.CutPrefix(param, "types."); isType {
+			constraint, err := params.String(key, "")
+			if err != nil {
+				return err
+			}
+			byName[name].types[strings.TrimPrefix(metavar, "$")] = constraint
+			continue
+		}
+		byName[name].values[param] = params[key]
+	}
+
+	patterns := make([]namedPattern, 0, len(byName))
+	for name, p := range byName {
+		if err := p.values.Check(patternKeys...); err != nil {
+			return fmt.Errorf("pattern %s: %w", name, err)
+		}
+
+		source, err := p.values.String("match", "")
+		if err != nil {
+			return fmt.Errorf("pattern %s: %w", name, err)
+		}
+		if source == "" {
+			return fmt.Errorf("pattern %s has no match parameter", name)
+		}
+		pattern, err := ParsePattern(source, p.types)
+		if err != nil {
+			return fmt.Errorf("pattern %s: %w", name, err)
+		}
+
+		message, err := p.values.String("message", "code matches pattern "+name)
+		if err != nil {
+			return fmt.Errorf("pattern %s: %w", name, err)
+		}
+		level, err := p.values.String("severity", "")
+		if err != nil {
+			return fmt.Errorf("pattern %s: %w", name, err)
+		}
+		var severity Severity
+		if level != "" {
+			if severity, err = ParseSeverity(level); err != nil {
+				return fmt.Errorf("pattern %s: %w", name, err)
+			}
+		}
+
+		patterns = append(patterns, namedPattern{name: name, pattern: pattern, message: message, severity: severity})
+	}
+	sort.Slice(patterns, func(i, j int) bool {
+		return patterns[i].name < patterns[j].name
+	})
+	r.patterns = patterns
+
+	return nil
+}
+
+// Visit matches the patterns against the node, the patterns of several statements against the statement lists
+func (r *PatternRule) Visit(ctx *Context, node ast.Node) {
+	stmts := stmtList(node)
+	for _, p := range r.patterns {
+		if bindings, ok := p.pattern.Match(node, ctx.TypesInfo); ok {
+			r.report(ctx, p, node, bindings)
+		}
+
+		for i := range stmts {
+			if bindings, n := p.pattern.MatchStmts(stmts[i:], ctx.TypesInfo); n > 0 {
+				r.report(ctx, p, stmts[i], bindings)
+			}
+		}
+	}
+}
+
+// report reports the match of the pattern on the node with the severity of the pattern
+func (r *PatternRule) report(ctx *Context, p namedPattern, node ast.Node, bindings Bindings) {
+	message := bindings.Expand(ctx.FileSet, p.message)
+	if p.severity == "" {
+		ctx.Report(node, message)
+		return
+	}
+
+	ctx.ReportWithSeverity(node, p.severity, message)
+}
+
+func init() {
+	RegisterRule(PatternRuleName, func() Rule {
+		return NewPatternRule()
+	})
+}
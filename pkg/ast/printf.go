@@ -0,0 +1,499 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ast
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+type (
+	// PrintfRule checks the calls of printf-like functions: the format must be constant,
+	// its verbs must match the number and the types of the arguments
+	// the functions are the fmt and log ones, the configured ones and, unless disabled, the wrappers declared
+	// in the package: functions ending with a format string and ...any passing them to a printf-like function
+	PrintfRule struct {
+		// functions are the full names of the printf-like functions, like fmt.Printf or (*log.Logger).Printf
+		functions map[string]bool
+
+		// infer treats the wrappers of printf-like functions declared in the package as printf-like
+		infer bool
+
+		// wrappers are the wrappers inferred from the files of the package, nil until the first call
+		wrappers map[*types.Func]bool
+	}
+
+	// formatVerb is a formatting directive of a format string
+	formatVerb struct {
+		// text of the directive, like %-8.3f
+		text string
+
+		// verb of the directive, like f
+		verb rune
+
+		// arg is the index of the argument formatted by the directive
+		arg int
+
+		// stars are the indexes of the arguments giving the width and the precision with *
+		stars []int
+	}
+)
+
+const (
+	// PrintfRuleName is the name of the PrintfRule
+	PrintfRuleName = "printf-format"
+)
+
+// defaultPrintfFunctions are the printf-like functions of the standard library
+var defaultPrintfFunctions = []string{
+	"fmt.Printf", "fmt.Sprintf", "fmt.Fprintf", "fmt.Errorf", "fmt.Appendf",
+	"log.Printf", "log.Fatalf", "log.Panicf",
+	"(*log.Logger).Printf", "(*log.Logger).Fatalf", "(*log.Logger).Panicf",
+	"(*testing.common).Errorf", "(*testing.common).Fatalf", "(*testing.common).Logf", "(*testing.common).Skipf",
+}
+
+// NewPrintfRule creates a new PrintfRule instance checking the standard library and the wrappers of the package
+func NewPrintfRule() *PrintfRule {
+	r := &PrintfRule{infer: true}
+	r.setFunctions(nil)
+
+	return r
+}
+
+// Name returns the name of the rule
+func (r *PrintfRule) Name() string {
+	return PrintfRuleName
+}
+
+// Doc returns the documentation of the rule
+func (r *PrintfRule) Doc() string {
+	return "checks the format strings and arguments of printf-like functions and their wrappers"
+}
+
+// Severity returns the default severity of the rule
+func (r *PrintfRule) Severity() Severity {
+	return SeverityWarning
+}
+
+// RequiresTypes reports that the rule needs the types of the called functions and of the arguments
+func (r *PrintfRule) RequiresTypes() bool {
+	return true
+}
+
+// Configure sets the functions parameter, the full names of printf-like functions added to the standard library ones,
+// e.g. github.com/LokiWager/analysis-demo/pkg/logger.Infof for the wrappers of other packages, and the infer parameter
+func (r *PrintfRule) Configure(params Params) error {
+	if err := params.Check("functions", "infer"); err != nil {
+		return err
+	}
+
+	functions, err := params.Strings("functions", nil)
+	if err != nil {
+		return err
+	}
+	if r.infer, err = params.Bool("infer", r.infer); err != nil {
+		return err
+	}
+	r.setFunctions(functions)
+
+	return nil
+}
+
+func (r *PrintfRule) setFunctions(functions []string) {
+	r.functions = make(map[string]bool, len(defaultPrintfFunctions)+len(functions))
+	for _, names := range [][]string{defaultPrintfFunctions, functions} {
+		for _, name := range names {
+			r.functions[name] = true
+		}
+	}
+}
+
+// Visit checks the calls of printf-like functions
+func (r *PrintfRule) Visit(ctx *Context, node ast.Node) {
+	call, ok := node.(*ast.CallExpr)
+	if !ok {
+		return
+	}
+
+	index := r.formatIndex(ctx, call)
+	// a call forwarding its arguments with args... cannot be checked
+	if index < 0 || index >= len(call.Args) || call.Ellipsis.IsValid() {
+		return
+	}
+
+	name := types.ExprString(call.Fun)
+	format, args := call.Args[index], call.Args[index+1:]
+	value := ctx.TypesInfo.Types[format].Value
+	if value == nil || value.Kind() != constant.String {
+		if len(args) == 0 {
+			ctx.ReportWithFixes(format, fmt.Sprintf("non-constant format string in call to %s", name), Fix{
+				Message: `insert "%s" format string`,
+				Edits: []TextEdit{{
+					Offset:  ctx.Offset(format.Pos()),
+					End:     ctx.Offset(format.Pos()),
+					NewText: `"%s", `,
+				}},
+			})
+		}
+		return
+	}
+
+	r.checkFormat(ctx, call, name, constant.StringVal(value), args)
+}
+
+// formatIndex returns the index of the format argument of the called function, -1 if it is not printf-like
+func (r *PrintfRule) formatIndex(ctx *Context, call *ast.CallExpr) int {
+	if r.wrappers == nil {
+		r.inferWrappers(ctx)
+	}
+
+	fn, ok := typeutil.Callee(ctx.TypesInfo, call).(*types.Func)
+	if !ok {
+		return -1
+	}
+
+	sig, ok := fn.Type().(*types.Signature)
+	if !ok || !isPrintfSignature(sig) {
+		return -1
+	}
+	index := sig.Params().Len() - 2
+
+	if r.functions[fn.FullName()] || r.wrappers[fn] {
+		return index
+	}
+	selector, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+	if sel := ctx.TypesInfo.Selections[selector]; ok && sel != nil {
+		if r.functions[fmt.Sprintf("(%s).%s", types.TypeString(sel.Recv(), nil), fn.Name())] {
+			return index
+		}
+	}
+
+	return -1
+}
+
+// inferWrappers finds the printf wrappers declared in the files of the package like vet does:
+// the functions ending with a format string and ...any whose body passes them as format, args... to a printf-like
+// function, wrappers of wrappers included
+func (r *PrintfRule) inferWrappers(ctx *Context) {
+	r.wrappers = make(map[*types.Func]bool)
+	if !r.infer {
+		return
+	}
+
+	var decls []*ast.FuncDecl
+	for _, file := range ctx.Package {
+		for _, decl := range file.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Body != nil {
+				decls = append(decls, fn)
+			}
+		}
+	}
+
+	// a wrapper may forward to a wrapper declared after it, repeat until no wrapper is found
+	for found := true; found; {
+		found = false
+		for _, decl := range decls {
+			fn, ok := ctx.TypesInfo.Defs[decl.Name].(*types.Func)
+			if ok && !r.wrappers[fn] && r.forwardsFormat(ctx, decl, fn) {
+				r.wrappers[fn] = true
+				found = true
+			}
+		}
+	}
+}
+
+// forwardsFormat reports whether the function has a printf signature and calls a printf-like function
+// with its format and args... as last arguments
+func (r *PrintfRule) forwardsFormat(ctx *Context, decl *ast.FuncDecl, fn *types.Func) bool {
+	sig, ok := fn.Type().(*types.Signature)
+	if !ok || !isPrintfSignature(sig) {
+		return false
+	}
+	params := sig.Params()
+	format, args := params.At(params.Len()-2), params.At(params.Len()-1)
+
+	forwards := false
+	ast.Inspect(decl.Body, func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if forwards || !ok || !call.Ellipsis.IsValid() || len(call.Args) < 2 {
+			return !forwards
+		}
+
+		n := len(call.Args)
+		if usesObject(ctx.TypesInfo, call.Args[n-2], format) && usesObject(ctx.TypesInfo, call.Args[n-1], args) {
+			forwards = r.formatIndex(ctx, call) == n-2
+		}
+		return !forwards
+	})
+
+	return forwards
+}
+
+// checkFormat checks the verbs of the format against the arguments
+func (r *PrintfRule) checkFormat(ctx *Context, call *ast.CallExpr, name, format string, args []ast.Expr) {
+	verbs, indexed, err := parseFormat(format)
+	if err != nil {
+		ctx.Reportf(call, "%s format %q %v", name, format, err)
+		return
+	}
+
+	needed := 0
+	for _, verb := range verbs {
+		for _, star := range verb.stars {
+			needed = max(needed, star+1)
+			if star < len(args) && !isIntType(ctx.TypesInfo.TypeOf(args[star])) {
+				ctx.Reportf(args[star], "%s format %s uses non-int %s as width or precision",
+					name, verb.text, types.ExprString(args[star]))
+			}
+		}
+		needed = max(needed, verb.arg+1)
+		if verb.arg >= len(args) {
+			continue
+		}
+
+		arg := args[verb.arg]
+		if !verbMatches(verb.verb, ctx.TypesInfo.TypeOf(arg), map[types.Type]bool{}) {
+			ctx.Reportf(arg, "%s format %s has argument %s of wrong type %s",
+				name, verb.text, types.ExprString(arg), ctx.TypesInfo.TypeOf(arg))
+		}
+	}
+
+	// with explicit argument indexes, arguments may be formatted twice or not at all
+	if needed > len(args) || (!indexed && needed < len(args)) {
+		ctx.Reportf(call, "%s format %q needs %d arguments but the call has %d", name, format, needed, len(args))
+	}
+}
+
+// isPrintfSignature reports whether the signature ends with a format string followed by ...any
+func isPrintfSignature(sig *types.Signature) bool {
+	params := sig.Params()
+	if !sig.Variadic() || params.Len() < 2 {
+		return false
+	}
+
+	format, ok := params.At(params.Len() - 2).Type().Underlying().(*types.Basic)
+	if !ok || format.Kind() != types.String {
+		return false
+	}
+	args, ok := params.At(params.Len() - 1).Type().(*types.Slice)
+	if !ok {
+		return false
+	}
+	iface, ok := args.Elem().Underlying().(*types.Interface)
+
+	return ok && iface.Empty()
+}
+
+// parseFormat parses the formatting directives of the format, %% is not a directive
+// indexed reports whether the format uses explicit argument indexes like %[2]d
+func parseFormat(format string) (verbs []formatVerb, indexed bool, err error) {
+	arg := 0
+	for i := 0; i < len(format); {
+		if format[i] != '%' {
+			i++
+			continue
+		}
+
+		start := i
+		i++
+		for i < len(format) && strings.IndexByte("+-# 0", format[i]) >= 0 {
+			i++
+		}
+
+		var stars []int
+		// width then precision, both may be an argument index and a * or a number
+		for part := 0; part < 2; part++ {
+			if part == 1 {
+				if i >= len(format) || format[i] != '.' {
+					break
+				}
+				i++
+			}
+			if i, arg, err = parseArgIndex(format, i, arg, &indexed); err != nil {
+				return nil, indexed, err
+			}
+			if i < len(format) && format[i] == '*' {
+				stars = append(stars, arg)
+				arg++
+				i++
+				continue
+			}
+			for i < len(format) && format[i] >= '0' && format[i] <= '9' {
+				i++
+			}
+		}
+		if i, arg, err = parseArgIndex(format, i, arg, &indexed); err != nil {
+			return nil, indexed, err
+		}
+
+		if i >= len(format) {
+			return nil, indexed, fmt.Errorf("ends with a %s directive without verb", format[start:])
+		}
+		verb, size := utf8.DecodeRuneInString(format[i:])
+		i += size
+		if verb == '%' && len(stars) == 0 {
+			continue
+		}
+		if strings.IndexRune("bcdeEfFgGoOpqstTUvwxX", verb) < 0 {
+			return nil, indexed, fmt.Errorf("has unknown verb %c in directive %s", verb, format[start:i])
+		}
+
+		verbs = append(verbs, formatVerb{text: format[start:i], verb: verb, arg: arg, stars: stars})
+		arg++
+	}
+
+	return verbs, indexed, nil
+}
+
+// parseArgIndex parses an explicit argument index [n] at i, it returns the position after it
+// and the index of the next argument
+func parseArgIndex(format string, i, arg int, indexed *bool) (int, int, error) {
+	if i >= len(format) || format[i] != '[' {
+		return i, arg, nil
+	}
+
+	end := strings.IndexByte(format[i:], ']')
+	if end < 0 {
+		return i, arg, fmt.Errorf("has unclosed argument index %s", format[i:])
+	}
+	n, err := strconv.Atoi(format[i+1 : i+end])
+	if err != nil || n < 1 {
+		return i, arg, fmt.Errorf("has invalid argument index %s", format[i:i+end+1])
+	}
+	*indexed = true
+
+	return i + end + 1, n - 1, nil
+}
+
+// verbMatches reports whether the verb can format a value of the type,
+// composite values are formatted element by element
+func verbMatches(verb rune, typ types.Type, seen map[types.Type]bool) bool {
+	if typ == nil || verb == 'v' || verb == 'T' || seen[typ] {
+		return true
+	}
+	seen[typ] = true
+
+	// a fmt.Formatter formats itself with any verb
+	if format := methodSignature(typ, "Format"); format != nil && format.Params().Len() == 2 {
+		return true
+	}
+	isError := types.Implements(typ, errorType.Underlying().(*types.Interface))
+	if verb == 'w' {
+		return isError
+	}
+	if stringer := methodSignature(typ, "String"); strings.ContainsRune("sqxX", verb) &&
+		(isError || stringer != nil && stringer.Params().Len() == 0 && stringer.Results().Len() == 1) {
+		return true
+	}
+
+	switch t := typ.Underlying().(type) {
+	case *types.Interface:
+		// the dynamic type is unknown
+		return true
+	case *types.Basic:
+		return basicVerbMatches(verb, t)
+	case *types.Pointer:
+		if verb == 'p' || strings.ContainsRune("bdoxX", verb) {
+			return true
+		}
+		// a pointer to a composite value is formatted as the value
+		switch t.Elem().Underlying().(type) {
+		case *types.Struct, *types.Array, *types.Slice, *types.Map:
+			return verbMatches(verb, t.Elem(), seen)
+		}
+		return false
+	case *types.Signature, *types.Chan:
+		return verb == 'p'
+	case *types.Slice:
+		if elem, ok := t.Elem().Underlying().(*types.Basic); ok && elem.Kind() == types.Byte &&
+			strings.ContainsRune("sqxX", verb) {
+			return true
+		}
+		return verb == 'p' || verbMatches(verb, t.Elem(), seen)
+	case *types.Array:
+		return verbMatches(verb, t.Elem(), seen)
+	case *types.Map:
+		return verb == 'p' || verbMatches(verb, t.Key(), seen) && verbMatches(verb, t.Elem(), seen)
+	case *types.Struct:
+		for i := 0; i < t.NumFields(); i++ {
+			if !verbMatches(verb, t.Field(i).Type(), seen) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return true
+}
+
+// basicVerbMatches reports whether the verb can format a value of the basic type
+func basicVerbMatches(verb rune, t *types.Basic) bool {
+	info := t.Info()
+	switch {
+	case t.Kind() == types.UnsafePointer:
+		return verb == 'p' || strings.ContainsRune("bdoxX", verb)
+	case info&types.IsBoolean != 0:
+		return verb == 't'
+	case info&types.IsInteger != 0:
+		return strings.ContainsRune("bcdoOqxXU", verb)
+	case info&types.IsFloat != 0, info&types.IsComplex != 0:
+		return strings.ContainsRune("beEfFgGxX", verb)
+	case info&types.IsString != 0:
+		return strings.ContainsRune("sqxX", verb)
+	}
+
+	return true
+}
+
+// methodSignature returns the signature of the method of the type or of its pointer, nil if it has none
+func methodSignature(typ types.Type, name string) *types.Signature {
+	obj, _, _ := types.LookupFieldOrMethod(typ, true, nil, name)
+	if fn, ok := obj.(*types.Func); ok {
+		return fn.Type().(*types.Signature)
+	}
+
+	return nil
+}
+
+// usesObject reports whether the expression is an identifier referring to the object
+func usesObject(info *types.Info, expr ast.Expr, obj types.Object) bool {
+	ident, ok := ast.Unparen(expr).(*ast.Ident)
+	return ok && info.Uses[ident] == obj
+}
+
+func isIntType(typ types.Type) bool {
+	if typ == nil {
+		return true
+	}
+
+	basic, ok := typ.Underlying().(*types.Basic)
+	return ok && basic.Info()&types.IsInteger != 0
+}
+
+func init() {
+	RegisterRule(PrintfRuleName, func() Rule {
+		return NewPrintfRule()
+	})
+}
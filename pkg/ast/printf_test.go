@@ -0,0 +1,93 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ast_test
+
+import (
+	"testing"
+
+	testAssert "github.com/stretchr/testify/assert"
+
+	"github.com/LokiWager/analysis-demo/pkg/ast"
+)
+
+// TestPrintfRule tests the printf-like functions given by the configuration
+func TestPrintfRule(t *testing.T) {
+	src := `
+package logger
+
+import "fmt"
+
+type buffer struct{}
+
+func (b *buffer) Writef(prefix string, args ...any) {
+	fmt.Println(append([]any{prefix}, args...)...)
+}
+
+func Infof(msg string, args ...interface{}) {}
+
+func Logf(format string, args ...interface{}) {
+	debugf(format, args...)
+}
+
+func debugf(format string, args ...interface{}) {
+	if format != "" {
+		fmt.Printf(format, args...)
+	}
+}
+
+func initSystem(b *buffer) {
+	Infof("listening on %d", "8080")
+	Logf("listening on %d", "8080")
+	b.Writef("prefix:", 1, 2)
+}
+`
+	fileSet, file, info := typeCheck(t, "logger.go", src)
+
+	tests := []struct {
+		name     string
+		params   ast.Params
+		expected []string
+	}{
+		{
+			name:     "Inferred wrappers",
+			params:   ast.Params{},
+			expected: []string{`Logf format %d has argument "8080" of wrong type string`},
+		},
+		{
+			name:     "Configured functions",
+			params:   ast.Params{"infer": false, "functions": "logger.Infof"},
+			expected: []string{`Infof format %d has argument "8080" of wrong type string`},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := testAssert.New(t)
+			rule := ast.NewPrintfRule()
+			assert.NoError(rule.Configure(test.params))
+
+			e := ast.NewEngineFromFile(fileSet, file)
+			e.SetTypesInfo(info)
+			var messages []string
+			for _, finding := range e.Run(rule) {
+				messages = append(messages, finding.Message)
+			}
+			assert.Equal(test.expected, messages)
+		})
+	}
+}
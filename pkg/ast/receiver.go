@@ -0,0 +1,178 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ast
+
+import (
+	"go/ast"
+	"sort"
+)
+
+type (
+	// ReceiverRule reports methods of a type mixing pointer and value receivers or using different receiver names,
+	// the methods of a type are compared across the files of the package, the findings of a file are its methods
+	ReceiverRule struct{}
+)
+
+const (
+	// ReceiverRuleName is the name of the ReceiverRule
+	ReceiverRuleName = "receiver-consistency"
+)
+
+// NewReceiverRule creates a new ReceiverRule instance
+func NewReceiverRule() *ReceiverRule {
+	return &ReceiverRule{}
+}
+
+// Name returns the name of the rule
+func (r *ReceiverRule) Name() string {
+	return ReceiverRuleName
+}
+
+// Doc returns the documentation of the rule
+func (r *ReceiverRule) Doc() string {
+	return "reports methods of a type mixing pointer and value receivers or using different receiver names"
+}
+
+// Severity returns the default severity of the rule
+func (r *ReceiverRule) Severity() Severity {
+	return SeverityWarning
+}
+
+// RequiresPackage reports that the rule compares the methods declared in the other files of the package
+func (r *ReceiverRule) RequiresPackage() bool {
+	return true
+}
+
+// Visit does nothing, the methods are collected from the files of the package once the file is done
+func (r *ReceiverRule) Visit(_ *Context, _ ast.Node) {
+}
+
+// Finish compares the methods of every type of the package,
+// the receivers of the file differing from the most used kind and name are reported
+func (r *ReceiverRule) Finish(ctx *Context) {
+	types, methodsOf := packageMethods(ctx.Package)
+	file := ctx.FileSet.File(ctx.File.Pos())
+	for _, typeName := range types {
+		methods := methodsOf[typeName]
+
+		pointers := 0
+		for _, fn := range methods {
+			if isPointerReceiver(fn) {
+				pointers++
+			}
+		}
+		// pointer receivers win a tie, they are what a type with any of them needs
+		pointerMajority := 2*pointers >= len(methods)
+		for _, fn := range methods {
+			if ctx.FileSet.File(fn.Pos()) != file || pointers == 0 || pointers == len(methods) ||
+				isPointerReceiver(fn) == pointerMajority {
+				continue
+			}
+			if pointerMajority {
+				ctx.Reportf(fn.Recv, "method %s has a value receiver, the other methods of %s have pointer receivers", FuncName(fn), typeName)
+			} else {
+				ctx.Reportf(fn.Recv, "method %s has a pointer receiver, the other methods of %s have value receivers", FuncName(fn), typeName)
+			}
+		}
+
+		name := mostUsedReceiverName(methods)
+		for _, fn := range methods {
+			if recv := receiverName(fn); ctx.FileSet.File(fn.Pos()) == file && recv != "" && recv != name {
+				ctx.Reportf(fn.Recv, "receiver name %s of method %s differs from %s used by the other methods of %s", recv, FuncName(fn), name, typeName)
+			}
+		}
+	}
+}
+
+// packageMethods returns the methods of the files keyed by receiver type name in source order,
+// with the type names in order of their first method
+func packageMethods(files []*ast.File) ([]string, map[string][]*ast.FuncDecl) {
+	var types []string
+	methods := make(map[string][]*ast.FuncDecl)
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv == nil || len(fn.Recv.List) == 0 {
+				continue
+			}
+
+			name := receiverTypeName(fn.Recv.List[0].Type)
+			if _, exists := methods[name]; !exists {
+				types = append(types, name)
+			}
+			methods[name] = append(methods[name], fn)
+		}
+	}
+
+	return types, methods
+}
+
+// mostUsedReceiverName returns the receiver name of most methods, the first one used on a tie
+func mostUsedReceiverName(methods []*ast.FuncDecl) string {
+	counts := make(map[string]int)
+	var names []string
+	for _, fn := range methods {
+		name := receiverName(fn)
+		if name == "" {
+			continue
+		}
+		if counts[name] == 0 {
+			names = append(names, name)
+		}
+		counts[name]++
+	}
+
+	sort.SliceStable(names, func(i, j int) bool {
+		return counts[names[i]] > counts[names[j]]
+	})
+	if len(names) == 0 {
+		return ""
+	}
+
+	return names[0]
+}
+
+// receiverName returns the name of the receiver, empty if it is unnamed or blank
+func receiverName(fn *ast.FuncDecl) string {
+	names := fn.Recv.List[0].Names
+	if len(names) == 0 || names[0].Name == "_" {
+		return ""
+	}
+
+	return names[0].Name
+}
+
+func isPointerReceiver(fn *ast.FuncDecl) bool {
+	expr := fn.Recv.List[0].Type
+	for {
+		paren, ok := expr.(*ast.ParenExpr)
+		if !ok {
+			break
+		}
+		expr = paren.X
+	}
+
+	_, ok := expr.(*ast.StarExpr)
+	return ok
+}
+
+func init() {
+	RegisterRule(ReceiverRuleName, func() Rule {
+		return NewReceiverRule()
+	})
+}
@@ -0,0 +1,352 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ast
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+)
+
+type (
+	// Severity is the severity level of a rule
+	Severity string
+
+	// Rule is a lint rule run by the Engine
+	// the engine walks the file once and calls the visit hooks of every rule on each node
+	Rule interface {
+		// Name returns the unique name of the rule, it is used as the rule ID
+		Name() string
+
+		// Doc returns the documentation of the rule
+		Doc() string
+
+		// Severity returns the default severity of the findings reported by the rule
+		Severity() Severity
+
+		// Visit is called when the traversal enters a node
+		Visit(ctx *Context, node ast.Node)
+	}
+
+	// LeaveRule is implemented by rules that need to know when the traversal leaves a node
+	LeaveRule interface {
+		Rule
+
+		// Leave is called when the traversal leaves a node, after all its children are visited
+		Leave(ctx *Context, node ast.Node)
+	}
+
+	// FinishRule is implemented by rules that need to report after the whole file is visited
+	FinishRule interface {
+		Rule
+
+		// Finish is called once the traversal of the file is done
+		Finish(ctx *Context)
+	}
+
+	// TypedRule is implemented by rules that need the type information of the file,
+	// the engine skips them when it has none
+	TypedRule interface {
+		Rule
+
+		// RequiresTypes reports whether the rule needs the type information of the file
+		RequiresTypes() bool
+	}
+
+	// PackageRule is implemented by rules comparing the declarations of all the files of a package,
+	// the engine gives them the files of the package of the file in Context.Package
+	PackageRule interface {
+		Rule
+
+		// RequiresPackage reports whether the rule needs the other files of the package
+		RequiresPackage() bool
+	}
+
+	// RuleFactory creates a new instance of a rule, rules keep per-file state,
+	// so every run gets its own instance
+	RuleFactory func() Rule
+
+	// Context is passed to the visit hooks of a rule
+	Context struct {
+		// FileSet is the file set of the source code
+		FileSet *token.FileSet
+
+		// File is the file being analyzed
+		File *ast.File
+
+		// Package are the files of the package of the file in the file set, File among them,
+		// only File if the engine does not know the package
+		Package []*ast.File
+
+		// TypesInfo is the type information of the file, nil unless the rule is a TypedRule
+		TypesInfo *types.Info
+
+		// rule is the rule the context belongs to
+		rule Rule
+
+		// walk is the traversal state shared by all rules
+		walk *walkState
+	}
+
+	// severityRule overrides the severity of a rule
+	severityRule struct {
+		Rule
+
+		// severity is the severity reported instead of the default one
+		severity Severity
+	}
+
+	walkState struct {
+		// stack of the ancestors of the current node, the current node is the last one
+		stack []ast.Node
+
+		// findings reported so far
+		findings []Finding
+	}
+)
+
+const (
+	// SeverityError is the severity of findings that must be fixed
+	SeverityError Severity = "error"
+
+	// SeverityWarning is the severity of findings that should be fixed
+	SeverityWarning Severity = "warning"
+
+	// SeverityInfo is the severity of informational findings
+	SeverityInfo Severity = "info"
+)
+
+// severityRanks orders the severities, the higher the more severe
+var severityRanks = map[Severity]int{
+	SeverityInfo:    1,
+	SeverityWarning: 2,
+	SeverityError:   3,
+}
+
+// ParseSeverity parses the name of a severity level
+func ParseSeverity(name string) (Severity, error) {
+	severity := Severity(name)
+	if _, exists := severityRanks[severity]; !exists {
+		return "", fmt.Errorf("severity %s not supported, use one of error, warning, info", name)
+	}
+
+	return severity, nil
+}
+
+// AtLeast reports whether the severity is at least as severe as the threshold
+func (s Severity) AtLeast(threshold Severity) bool {
+	return severityRanks[s] >= severityRanks[threshold]
+}
+
+// RulesVersion is the version of the built-in rules, bump it in every change of the findings of a rule,
+// so results cached by earlier versions are not reused. The cache key also holds the name, documentation
+// and default severity of every enabled rule, which covers added, removed and documented changes only
+const RulesVersion = 8
+
+// RuleRegistry holds the factories of all registered rules, keyed by the rule name
+var RuleRegistry = map[string]RuleFactory{}
+
+// optInRules are the names of the registered rules only run when enabled by name
+var optInRules = map[string]bool{}
+
+// RegisterRule registers a rule factory under the given name,
+// a rule registered with an existing name replaces the previous one
+func RegisterRule(name string, factory RuleFactory) {
+	RuleRegistry[name] = factory
+	delete(optInRules, name)
+}
+
+// RegisterOptInRule is like RegisterRule but the rule is not run by default,
+// only when it is enabled by name
+func RegisterOptInRule(name string, factory RuleFactory) {
+	RegisterRule(name, factory)
+	optInRules[name] = true
+}
+
+// RuleNames returns the names of all registered rules in alphabetical order
+func RuleNames() []string {
+	names := make([]string, 0, len(RuleRegistry))
+	for name := range RuleRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// DefaultRuleNames returns the names of the registered rules run by default, all but the opt-in ones,
+// in alphabetical order
+func DefaultRuleNames() []string {
+	names := make([]string, 0, len(RuleRegistry))
+	for _, name := range RuleNames() {
+		if !optInRules[name] {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// NewRules creates the rules to run
+// enable is the list of rules to run, if empty, the default rules are run
+// disable is the list of rules to skip, it takes precedence over enable
+func NewRules(enable, disable []string) ([]Rule, error) {
+	if len(enable) == 0 {
+		enable = DefaultRuleNames()
+	}
+
+	skip := make(map[string]bool, len(disable))
+	for _, name := range disable {
+		if _, exists := RuleRegistry[name]; !exists {
+			return nil, fmt.Errorf("rule %s not found", name)
+		}
+		skip[name] = true
+	}
+
+	rules := make([]Rule, 0, len(enable))
+	seen := make(map[string]bool, len(enable))
+	for _, name := range enable {
+		factory, exists := RuleRegistry[name]
+		if !exists {
+			return nil, fmt.Errorf("rule %s not found", name)
+		}
+		if skip[name] || seen[name] {
+			continue
+		}
+		seen[name] = true
+		rules = append(rules, factory())
+	}
+
+	return rules, nil
+}
+
+// WithSeverity returns the rule reporting its findings with the given severity
+func WithSeverity(rule Rule, severity Severity) Rule {
+	if wrapped, ok := rule.(*severityRule); ok {
+		rule = wrapped.Rule
+	}
+
+	return &severityRule{Rule: rule, severity: severity}
+}
+
+// OverrideSeverities overrides the severities of the rules, keyed by rule name
+func OverrideSeverities(rules []Rule, severities map[string]Severity) []Rule {
+	overridden := make([]Rule, 0, len(rules))
+	for _, rule := range rules {
+		if severity, exists := severities[rule.Name()]; exists {
+			rule = WithSeverity(rule, severity)
+		}
+		overridden = append(overridden, rule)
+	}
+
+	return overridden
+}
+
+// Severity returns the overridden severity
+func (r *severityRule) Severity() Severity {
+	return r.severity
+}
+
+// Leave forwards to the wrapped rule if it implements LeaveRule
+func (r *severityRule) Leave(ctx *Context, node ast.Node) {
+	if leaver, ok := r.Rule.(LeaveRule); ok {
+		leaver.Leave(ctx, node)
+	}
+}
+
+// Finish forwards to the wrapped rule if it implements FinishRule
+func (r *severityRule) Finish(ctx *Context) {
+	if finisher, ok := r.Rule.(FinishRule); ok {
+		finisher.Finish(ctx)
+	}
+}
+
+// RequiresTypes forwards to the wrapped rule if it implements TypedRule
+func (r *severityRule) RequiresTypes() bool {
+	return RequiresTypes(r.Rule)
+}
+
+// RequiresTypes reports whether the rule needs the type information of the file
+func RequiresTypes(rule Rule) bool {
+	typed, ok := rule.(TypedRule)
+	return ok && typed.RequiresTypes()
+}
+
+// RequiresPackage reports whether the rule needs the other files of the package of the file
+func RequiresPackage(rule Rule) bool {
+	pkg, ok := rule.(PackageRule)
+	return ok && pkg.RequiresPackage()
+}
+
+// Report reports a finding on the node for the rule owning the context
+func (c *Context) Report(node ast.Node, message string) {
+	c.ReportWithFixes(node, message)
+}
+
+// ReportWithFixes is like Report but attaches suggested fixes to the finding
+func (c *Context) ReportWithFixes(node ast.Node, message string, fixes ...Fix) {
+	c.report(node, c.rule.Severity(), message, fixes)
+}
+
+// ReportWithSeverity is like Report but with the severity of the finding instead of the severity of the rule,
+// for rules whose findings come with their own severity
+func (c *Context) ReportWithSeverity(node ast.Node, severity Severity, message string) {
+	c.report(node, severity, message, nil)
+}
+
+// report appends the finding on the node
+func (c *Context) report(node ast.Node, severity Severity, message string, fixes []Fix) {
+	c.walk.findings = append(c.walk.findings, Finding{
+		Rule:     c.rule.Name(),
+		Severity: severity,
+		Message:  message,
+		Subject:  subjectOf(c.FileSet, node),
+		Position: c.FileSet.Position(node.Pos()),
+		End:      c.FileSet.Position(node.End()),
+		Fixes:    fixes,
+		Pos:      node.Pos(),
+		EndPos:   node.End(),
+	})
+}
+
+// Offset returns the byte offset of the position in the file, used to build text edits
+func (c *Context) Offset(pos token.Pos) int {
+	return c.FileSet.Position(pos).Offset
+}
+
+// Reportf is like Report but formats the message
+func (c *Context) Reportf(node ast.Node, format string, args ...any) {
+	c.Report(node, fmt.Sprintf(format, args...))
+}
+
+// Stack returns the ancestors of the current node from the root, the current node is the last one
+// the returned slice must not be modified
+func (c *Context) Stack() []ast.Node {
+	return c.walk.stack
+}
+
+// Parent returns the parent of the current node, nil if the current node is the root
+func (c *Context) Parent() ast.Node {
+	if len(c.walk.stack) < 2 {
+		return nil
+	}
+
+	return c.walk.stack[len(c.walk.stack)-2]
+}
@@ -0,0 +1,400 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ast_test
+
+import (
+	goast "go/ast"
+	"testing"
+
+	testAssert "github.com/stretchr/testify/assert"
+
+	"github.com/LokiWager/analysis-demo/pkg/ast"
+)
+
+type funcCountRule struct {
+	count int
+}
+
+func (r *funcCountRule) Name() string           { return "func-count" }
+func (r *funcCountRule) Doc() string            { return "reports every function declaration" }
+func (r *funcCountRule) Severity() ast.Severity { return ast.SeverityInfo }
+
+func (r *funcCountRule) Visit(ctx *ast.Context, node goast.Node) {
+	if fn, ok := node.(*goast.FuncDecl); ok {
+		r.count++
+		ctx.Reportf(fn, "function %s", fn.Name.Name)
+	}
+}
+
+func (r *funcCountRule) Finish(ctx *ast.Context) {
+	ctx.Reportf(ctx.File, "%d functions", r.count)
+}
+
+// TestEngine_Run tests running built-in and custom rules in one traversal
+func TestEngine_Run(t *testing.T) {
+	t.Run("Run built-in and custom rules", func(t *testing.T) {
+		assert := testAssert.New(t)
+		src := `
+package main
+
+func idEqual13xxxx() {
+}
+
+func main() {
+}
+`
+		e, err := ast.NewEngine("", src)
+		assert.NoError(err)
+		findings := e.Run(ast.NewIdentLengthRule(), ast.NewNestingRule(), &funcCountRule{})
+
+		assert.Len(findings, 4)
+		assert.Equal("2 functions", findings[0].Message)
+		assert.Equal("function idEqual13xxxx", findings[1].Message)
+		assert.Equal(ast.IdentLengthRuleName, findings[2].Rule)
+		assert.Equal("function main", findings[3].Message)
+	})
+}
+
+// TestNewRules tests creating rules from the registry
+func TestNewRules(t *testing.T) {
+	t.Run("NewRules with the default rules", func(t *testing.T) {
+		assert := testAssert.New(t)
+		rules, err := ast.NewRules(nil, nil)
+		assert.NoError(err)
+		assert.Len(rules, len(ast.DefaultRuleNames()))
+		assert.NotContains(ast.DefaultRuleNames(), ast.MagicLiteralRuleName)
+		assert.Contains(ast.RuleNames(), ast.MagicLiteralRuleName)
+	})
+
+	t.Run("NewRules with an opt-in rule", func(t *testing.T) {
+		assert := testAssert.New(t)
+		rules, err := ast.NewRules([]string{ast.MagicLiteralRuleName}, nil)
+		assert.NoError(err)
+		if assert.Len(rules, 1) {
+			assert.Equal(ast.MagicLiteralRuleName, rules[0].Name())
+		}
+	})
+
+	t.Run("NewRules with disabled rules", func(t *testing.T) {
+		assert := testAssert.New(t)
+		rules, err := ast.NewRules(nil, []string{ast.IdentLengthRuleName})
+		assert.NoError(err)
+		for _, rule := range rules {
+			assert.NotEqual(ast.IdentLengthRuleName, rule.Name())
+		}
+	})
+
+	t.Run("NewRules with unknown rule", func(t *testing.T) {
+		assert := testAssert.New(t)
+		_, err := ast.NewRules([]string{"unknown"}, nil)
+		assert.Error(err)
+	})
+
+	t.Run("NewRules with registered rule", func(t *testing.T) {
+		assert := testAssert.New(t)
+		ast.RegisterRule("func-count", func() ast.Rule {
+			return &funcCountRule{}
+		})
+		defer delete(ast.RuleRegistry, "func-count")
+
+		rules, err := ast.NewRules([]string{"func-count"}, nil)
+		assert.NoError(err)
+		assert.Len(rules, 1)
+		assert.Equal(ast.SeverityInfo, rules[0].Severity())
+	})
+}
+
+// TestWithSeverity tests overriding the severity of a rule
+func TestWithSeverity(t *testing.T) {
+	t.Run("WithSeverity keeps the visit hooks", func(t *testing.T) {
+		assert := testAssert.New(t)
+		src := `
+package main
+
+func main() {
+	for {
+		if true {
+			switch {
+			default:
+				select {
+				default:
+					if false {
+						return
+					}
+				}
+			}
+		}
+	}
+}
+`
+		rules := ast.OverrideSeverities(
+			[]ast.Rule{ast.NewNestingRule(), &funcCountRule{}},
+			map[string]ast.Severity{ast.NestingRuleName: ast.SeverityError, "func-count": ast.SeverityWarning},
+		)
+		e, err := ast.NewEngine("", src)
+		assert.NoError(err)
+		findings := e.Run(rules...)
+
+		assert.Len(findings, 3)
+		assert.Equal("1 functions", findings[0].Message)
+		assert.Equal(ast.SeverityWarning, findings[0].Severity)
+		assert.Equal(ast.NestingRuleName, findings[2].Rule)
+		assert.Equal(ast.SeverityError, findings[2].Severity)
+	})
+
+	t.Run("Severity order", func(t *testing.T) {
+		assert := testAssert.New(t)
+		assert.True(ast.SeverityError.AtLeast(ast.SeverityWarning))
+		assert.True(ast.SeverityWarning.AtLeast(ast.SeverityWarning))
+		assert.False(ast.SeverityInfo.AtLeast(ast.SeverityWarning))
+
+		_, err := ast.ParseSeverity("fatal")
+		assert.Error(err)
+	})
+}
+
+// TestConfigureRules tests applying parameters to the rules
+func TestConfigureRules(t *testing.T) {
+	src := `
+package main
+
+func main() {
+	for {
+		if true {
+			switch {
+			default:
+				return
+			}
+		}
+	}
+}
+`
+
+	t.Run("ConfigureRules with a lower nesting limit", func(t *testing.T) {
+		assert := testAssert.New(t)
+		rules := ast.OverrideSeverities(
+			[]ast.Rule{ast.NewNestingRule()},
+			map[string]ast.Severity{ast.NestingRuleName: ast.SeverityError},
+		)
+		err := ast.ConfigureRules(rules, map[string]ast.Params{ast.NestingRuleName: {"max-depth": "2"}})
+		assert.NoError(err)
+
+		e, err := ast.NewEngine("", src)
+		assert.NoError(err)
+		findings := e.Run(rules...)
+
+		if assert.Len(findings, 1) {
+			assert.Equal(ast.SeverityError, findings[0].Severity)
+			assert.Equal("control flow nested 3 levels deep, more than 2: "+
+				"func main > for (line 5) > if (line 6) > switch (line 7)", findings[0].Message)
+		}
+	})
+
+	t.Run("ConfigureRules with function shape limits", func(t *testing.T) {
+		assert := testAssert.New(t)
+		rules := []ast.Rule{ast.NewFunctionLengthRule(), ast.NewFunctionParamsRule(), ast.NewNakedReturnRule()}
+		err := ast.ConfigureRules(rules, map[string]ast.Params{
+			ast.FunctionLengthRuleName: {"max-statements": 2, "max-lines": 0},
+			ast.FunctionParamsRuleName: {"max-params": 1},
+			ast.NakedReturnRuleName:    {"max-lines": 1},
+		})
+		assert.NoError(err)
+
+		e, err := ast.NewEngine("", `
+package main
+
+func split(s string, sep byte) (head, tail string) {
+	head, tail = s, ""
+	for i := range s {
+		if s[i] == sep {
+			head, tail = s[:i], s[i+1:]
+			break
+		}
+	}
+	return
+}
+`)
+		assert.NoError(err)
+
+		var messages []string
+		for _, finding := range e.Run(rules...) {
+			messages = append(messages, finding.Message)
+		}
+		assert.Equal([]string{
+			"function split has 6 statements, more than 2",
+			"function split has 2 parameters, more than 1",
+			"naked return in function split with 8 lines, more than 1",
+		}, messages)
+	})
+
+	t.Run("ConfigureRules with a magic literal allow-list", func(t *testing.T) {
+		assert := testAssert.New(t)
+		src := `
+package main
+
+import "fmt"
+
+func main() {
+	fmt.Println("listening on", 8080, 8443)
+	listen(8080, 2.50, "tcp")
+}
+`
+		rules := []ast.Rule{ast.NewMagicLiteralRule()}
+		err := ast.ConfigureRules(rules, map[string]ast.Params{
+			ast.MagicLiteralRuleName: {"allow-numbers": "8080, 2.5", "ignore-calls": []string{}},
+		})
+		assert.NoError(err)
+
+		e, err := ast.NewEngine("main.go", src)
+		assert.NoError(err)
+		var messages []string
+		for _, finding := range e.Run(rules...) {
+			messages = append(messages, finding.Message)
+		}
+		assert.Equal([]string{
+			`magic string "listening on", extract it to a named constant`,
+			"magic number 8443, extract it to a named constant",
+			`magic string "tcp", extract it to a named constant`,
+		}, messages)
+
+		// test files are ignored by default
+		e, err = ast.NewEngine("main_test.go", src)
+		assert.NoError(err)
+		assert.Empty(e.Run(ast.NewMagicLiteralRule()))
+
+		assert.Error(ast.ConfigureRules(rules, map[string]ast.Params{ast.MagicLiteralRuleName: {"allow-numbers": "one"}}))
+	})
+
+	t.Run("ConfigureRules with a struct tag key style", func(t *testing.T) {
+		assert := testAssert.New(t)
+		rules := []ast.Rule{ast.NewStructTagRule()}
+		err := ast.ConfigureRules(rules, map[string]ast.Params{
+			ast.StructTagRuleName: {"key-style": ast.KeyStyleSnake, "keys": "json"},
+		})
+		assert.NoError(err)
+
+		e, err := ast.NewEngine("", `
+package service
+
+type ProcessInfo struct {
+	NumFDs     int32 `+"`json:\"numFDs\" yaml:\"numFDs\"`"+`
+	NumThreads int32 `+"`json:\"num_threads\"`"+`
+}
+`)
+		assert.NoError(err)
+		findings := e.Run(rules...)
+		if assert.Len(findings, 1) {
+			assert.Equal("json name numFDs is not snake_case", findings[0].Message)
+		}
+
+		assert.Error(ast.ConfigureRules(rules, map[string]ast.Params{ast.StructTagRuleName: {"key-style": "PascalCase"}}))
+	})
+
+	t.Run("ConfigureRules with a license header template", func(t *testing.T) {
+		assert := testAssert.New(t)
+		rules := []ast.Rule{ast.NewLicenseHeaderRule()}
+		err := ast.ConfigureRules(rules, map[string]ast.Params{
+			ast.LicenseHeaderRuleName: {"template": "Copyright {year} {holder}\nSPDX-License-Identifier: MIT", "holder": "LokiWager"},
+		})
+		assert.NoError(err)
+
+		for src, message := range map[string]string{
+			"// Copyright 2021-2024 LokiWager\n// SPDX-License-Identifier: MIT\n\npackage main\n":     "",
+			"/*\n * Copyright 2024 LokiWager\n * SPDX-License-Identifier: MIT\n */\n\npackage main\n": "",
+			"// Copyright 2024 Someone\n// SPDX-License-Identifier: MIT\n\npackage main\n": "license header does not match the template, " +
+				`line 1 should be "Copyright {year} {holder}"`,
+			"// Package main is a command\npackage main\n": "missing license header",
+		} {
+			e, err := ast.NewEngine("main.go", src)
+			assert.NoError(err)
+			findings := e.Run(rules...)
+			if message == "" {
+				assert.Empty(findings, src)
+			} else if assert.Len(findings, 1, src) {
+				assert.Equal(message, findings[0].Message)
+				assert.Len(findings[0].Fixes, 1)
+			}
+		}
+
+		assert.Error(ast.ConfigureRules(rules, map[string]ast.Params{ast.LicenseHeaderRuleName: {"year": "last"}}))
+	})
+
+	t.Run("ConfigureRules with doc comments of test files", func(t *testing.T) {
+		assert := testAssert.New(t)
+		src := `
+package lint
+
+const (
+	// MaxDepth is the maximum nesting depth
+	MaxDepth = 4
+	MaxLength = 80
+)
+
+func TestRun(t *testing.T) {}
+`
+		e, err := ast.NewEngine("lint_test.go", src)
+		assert.NoError(err)
+		assert.Empty(e.Run(ast.NewDocCommentRule()))
+
+		rules := []ast.Rule{ast.NewDocCommentRule()}
+		assert.NoError(ast.ConfigureRules(rules, map[string]ast.Params{ast.DocCommentRuleName: {"ignore-tests": false}}))
+		e, err = ast.NewEngine("lint_test.go", src)
+		assert.NoError(err)
+		var messages []string
+		for _, finding := range e.Run(rules...) {
+			messages = append(messages, finding.Message)
+		}
+		assert.Equal([]string{
+			"exported const MaxLength should have a doc comment",
+			"exported func TestRun should have a doc comment",
+		}, messages)
+	})
+
+	t.Run("ConfigureRules ignores rules that do not run", func(t *testing.T) {
+		assert := testAssert.New(t)
+		err := ast.ConfigureRules(nil, map[string]ast.Params{ast.NestingRuleName: {"max-depth": 2}})
+		assert.NoError(err)
+	})
+
+	t.Run("ConfigureRules with invalid parameters", func(t *testing.T) {
+		assert := testAssert.New(t)
+		rules := []ast.Rule{ast.NewNestingRule(), ast.NewIdentLengthRule()}
+
+		assert.Error(ast.ConfigureRules(rules, map[string]ast.Params{ast.NestingRuleName: {"max-level": 2}}))
+		assert.Error(ast.ConfigureRules(rules, map[string]ast.Params{ast.NestingRuleName: {"max-depth": "two"}}))
+		assert.Error(ast.ConfigureRules(rules, map[string]ast.Params{ast.NestingRuleName: {"max-depth": 0}}))
+		assert.Error(ast.ConfigureRules(rules, map[string]ast.Params{ast.IdentLengthRuleName: {"length": 13}}))
+		assert.Error(ast.ConfigureRules(rules, map[string]ast.Params{"unknown": {"max-depth": 2}}))
+	})
+
+	t.Run("Limits", func(t *testing.T) {
+		assert := testAssert.New(t)
+		maxParams, maxResults := 5, 3
+		limits := map[string]*int{"max-params": &maxParams, "max-results": &maxResults}
+
+		assert.NoError(ast.Params{"max-results": 0}.Limits(0, limits))
+		assert.Equal(5, maxParams)
+		assert.Equal(0, maxResults)
+
+		// no limit is set when one is invalid
+		assert.EqualError(ast.Params{"max-params": 2, "max-results": -1}.Limits(0, limits),
+			"parameter max-results must be at least 0, got -1")
+		assert.Equal(5, maxParams)
+		assert.Error(ast.Params{"max-param": 2}.Limits(0, limits))
+	})
+}
@@ -0,0 +1,404 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ast
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type (
+	// StructTagRule reports malformed struct tags, duplicate names of the checked keys within a struct,
+	// names not following the naming style of the keys and exported fields missing a key their siblings have,
+	// the naming style is inferred from the names of all the files of the package
+	StructTagRule struct {
+		// keys are the tag keys whose names are checked, like json
+		keys []string
+
+		// keyStyle is the naming style of the names, inferred from the majority of the package if empty
+		keyStyle string
+
+		// names are the names of the checked keys in the file, their style is checked once the file is visited
+		names []tagName
+	}
+
+	// tagPair is a key:"value" pair of a struct tag
+	tagPair struct {
+		key   string
+		value string
+	}
+
+	// tagName is the name given by a checked key of a struct tag
+	tagName struct {
+		// tag is the tag of the name
+		tag *ast.BasicLit
+
+		// key is the tag key, like json
+		key string
+
+		// name is the name given by the key
+		name string
+
+		// style is the naming style of the name, empty if it has a single word
+		style string
+	}
+)
+
+const (
+	// StructTagRuleName is the name of the StructTagRule
+	StructTagRuleName = "struct-tag"
+
+	// KeyStyleCamel is the camelCase naming style of tag names, like numFDs
+	KeyStyleCamel = "camelCase"
+
+	// KeyStyleSnake is the snake_case naming style of tag names, like cpu_percent
+	KeyStyleSnake = "snake_case"
+
+	// KeyStyleKebab is the kebab-case naming style of tag names, like cpu-percent
+	KeyStyleKebab = "kebab-case"
+
+	// keyStyleMixed is the style of names mixing styles, like cpu_Percent
+	keyStyleMixed = "mixed"
+)
+
+// defaultTagKeys are the tag keys checked by default
+var defaultTagKeys = []string{"json", "yaml", "bson"}
+
+// NewStructTagRule creates a new StructTagRule instance checking the json, yaml and bson keys
+// in the naming style of the majority of the package
+func NewStructTagRule() *StructTagRule {
+	return &StructTagRule{keys: defaultTagKeys}
+}
+
+// Name returns the name of the rule
+func (r *StructTagRule) Name() string {
+	return StructTagRuleName
+}
+
+// Doc returns the documentation of the rule
+func (r *StructTagRule) Doc() string {
+	return "reports malformed struct tags, duplicate or inconsistently named json, yaml and bson names and missing tags"
+}
+
+// Severity returns the default severity of the rule
+func (r *StructTagRule) Severity() Severity {
+	return SeverityWarning
+}
+
+// Configure sets the keys parameter and the key-style parameter, one of camelCase, snake_case or kebab-case
+func (r *StructTagRule) Configure(params Params) error {
+	if err := params.Check("keys", "key-style"); err != nil {
+		return err
+	}
+
+	keys, err := params.Strings("keys", r.keys)
+	if err != nil {
+		return err
+	}
+	style, err := params.String("key-style", r.keyStyle)
+	if err != nil {
+		return err
+	}
+	switch style {
+	case "", KeyStyleCamel, KeyStyleSnake, KeyStyleKebab:
+	default:
+		return fmt.Errorf("key-style %s not supported, use one of %s, %s, %s", style, KeyStyleCamel, KeyStyleSnake, KeyStyleKebab)
+	}
+	r.keys, r.keyStyle = keys, style
+
+	return nil
+}
+
+// RequiresPackage reports that the rule infers the naming style from the other files of the package
+func (r *StructTagRule) RequiresPackage() bool {
+	return true
+}
+
+// Visit checks the tags of the fields of every struct type
+func (r *StructTagRule) Visit(ctx *Context, node ast.Node) {
+	st, ok := node.(*ast.StructType)
+	if !ok || st.Fields == nil {
+		return
+	}
+
+	structName := "struct literal"
+	if spec, ok := ctx.Parent().(*ast.TypeSpec); ok {
+		structName = spec.Name.Name
+	}
+
+	// keys used by the fields, and the field using every name of every key
+	used := make(map[string]bool)
+	owners := make(map[string]string)
+	tagged := make(map[*ast.Field]map[string]bool)
+	for _, field := range st.Fields.List {
+		pairs, ok := r.parseFieldTag(ctx, field)
+		if !ok {
+			// a malformed tag is neither missing nor checked
+			tagged[field] = nil
+			continue
+		}
+
+		tagged[field] = make(map[string]bool, len(pairs))
+		for _, pair := range pairs {
+			if !r.checked(pair.key) {
+				continue
+			}
+			tagged[field][pair.key] = true
+
+			// only explicit names make the tag expected on the sibling fields,
+			// not options keeping the field name or - leaving the field out
+			name, _, _ := strings.Cut(pair.value, ",")
+			used[pair.key] = used[pair.key] || name != "" && pair.value != "-"
+			if name == "" || name == "-" && pair.value == "-" {
+				continue
+			}
+			if name != "-" {
+				r.names = append(r.names, tagName{tag: field.Tag, key: pair.key, name: name, style: keyStyle(name)})
+			}
+
+			owner := fmt.Sprintf("%s %s", pair.key, name)
+			if other, exists := owners[owner]; exists {
+				ctx.Reportf(field.Tag, "struct %s has duplicate %s name %s, also used by field %s",
+					structName, pair.key, name, other)
+				continue
+			}
+			owners[owner] = fieldName(field)
+		}
+	}
+
+	for _, field := range st.Fields.List {
+		if tagged[field] == nil || len(field.Names) == 0 {
+			continue
+		}
+
+		var missing []string
+		for _, key := range r.keys {
+			if used[key] && !tagged[field][key] {
+				missing = append(missing, key)
+			}
+		}
+		for _, name := range field.Names {
+			if name.IsExported() && len(missing) > 0 {
+				ctx.Reportf(name, "field %s of %s has no %s tag, its sibling fields have one",
+					name.Name, structName, strings.Join(missing, ", "))
+			}
+		}
+	}
+}
+
+// Finish checks the naming style of the names of the file
+func (r *StructTagRule) Finish(ctx *Context) {
+	style := r.keyStyle
+	inferred := style == ""
+	if inferred {
+		names := r.names
+		for _, file := range ctx.Package {
+			if file != ctx.File {
+				names = append(names, r.fileTagNames(file)...)
+			}
+		}
+		style = majorityStyle(names)
+	}
+	if style == "" {
+		return
+	}
+
+	for _, name := range r.names {
+		if name.style == "" || name.style == style {
+			continue
+		}
+		if inferred {
+			ctx.Reportf(name.tag, "%s name %s is not %s like the other names of the package", name.key, name.name, style)
+		} else {
+			ctx.Reportf(name.tag, "%s name %s is not %s", name.key, name.name, style)
+		}
+	}
+}
+
+// parseFieldTag parses the tag of the field and reports its syntax errors, false if it is malformed
+func (r *StructTagRule) parseFieldTag(ctx *Context, field *ast.Field) ([]tagPair, bool) {
+	if field.Tag == nil {
+		return nil, true
+	}
+
+	tag, err := strconv.Unquote(field.Tag.Value)
+	if err == nil {
+		var pairs []tagPair
+		if pairs, err = parseStructTag(tag); err == nil {
+			return pairs, true
+		}
+	}
+	ctx.Reportf(field.Tag, "struct field tag %s of %s: %v", field.Tag.Value, fieldName(field), err)
+
+	return nil, false
+}
+
+// fileTagNames returns the names of the checked keys in the well-formed tags of the file
+func (r *StructTagRule) fileTagNames(file *ast.File) []tagName {
+	var names []tagName
+	ast.Inspect(file, func(node ast.Node) bool {
+		field, ok := node.(*ast.Field)
+		if !ok || field.Tag == nil {
+			return true
+		}
+
+		tag, err := strconv.Unquote(field.Tag.Value)
+		if err != nil {
+			return true
+		}
+		pairs, err := parseStructTag(tag)
+		if err != nil {
+			return true
+		}
+		for _, pair := range pairs {
+			if name, _, _ := strings.Cut(pair.value, ","); r.checked(pair.key) && name != "" && name != "-" {
+				names = append(names, tagName{tag: field.Tag, key: pair.key, name: name, style: keyStyle(name)})
+			}
+		}
+		return true
+	})
+
+	return names
+}
+
+func (r *StructTagRule) checked(key string) bool {
+	for _, checked := range r.keys {
+		if key == checked {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseStructTag parses the tag in the conventional format of reflect.StructTag, space separated key:"value" pairs
+func parseStructTag(tag string) ([]tagPair, error) {
+	var pairs []tagPair
+	seen := make(map[string]bool)
+	for tag != "" {
+		i := 0
+		for i < len(tag) && tag[i] == ' ' {
+			i++
+		}
+		if i == 0 && len(pairs) > 0 {
+			return nil, errors.New(`key:"value" pairs not separated by spaces`)
+		}
+		if tag = tag[i:]; tag == "" {
+			break
+		}
+
+		i = 0
+		for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+			i++
+		}
+		if i == 0 {
+			return nil, errors.New("bad syntax for struct tag key")
+		}
+		if i+1 >= len(tag) || tag[i] != ':' {
+			return nil, errors.New("bad syntax for struct tag pair")
+		}
+		if tag[i+1] != '"' {
+			return nil, errors.New("bad syntax for struct tag value")
+		}
+		key := tag[:i]
+		tag = tag[i+1:]
+
+		i = 1
+		for i < len(tag) && tag[i] != '"' {
+			if tag[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(tag) {
+			return nil, errors.New("bad syntax for struct tag value")
+		}
+		value, err := strconv.Unquote(tag[:i+1])
+		if err != nil {
+			return nil, errors.New("bad syntax for struct tag value")
+		}
+		tag = tag[i+1:]
+
+		if seen[key] {
+			return nil, fmt.Errorf("duplicate struct tag key %s", key)
+		}
+		seen[key] = true
+		pairs = append(pairs, tagPair{key: key, value: value})
+	}
+
+	return pairs, nil
+}
+
+// keyStyle returns the naming style of the name, empty for a single lower case word
+func keyStyle(name string) string {
+	snake, kebab := strings.Contains(name, "_"), strings.Contains(name, "-")
+	camel := strings.IndexFunc(name, unicode.IsUpper) >= 0
+	switch {
+	case snake && !kebab && !camel:
+		return KeyStyleSnake
+	case kebab && !snake && !camel:
+		return KeyStyleKebab
+	case camel && !snake && !kebab:
+		return KeyStyleCamel
+	case snake || kebab || camel:
+		return keyStyleMixed
+	}
+
+	return ""
+}
+
+// majorityStyle returns the most used naming style of the names, camelCase wins a tie
+func majorityStyle(names []tagName) string {
+	counts := make(map[string]int)
+	for _, name := range names {
+		if name.style != "" && name.style != keyStyleMixed {
+			counts[name.style]++
+		}
+	}
+
+	styles := []string{KeyStyleCamel, KeyStyleSnake, KeyStyleKebab}
+	sort.SliceStable(styles, func(i, j int) bool {
+		return counts[styles[i]] > counts[styles[j]]
+	})
+	if counts[styles[0]] == 0 {
+		return ""
+	}
+
+	return styles[0]
+}
+
+// fieldName returns the name of the field, the type of an embedded field
+func fieldName(field *ast.Field) string {
+	if len(field.Names) > 0 {
+		return field.Names[0].Name
+	}
+
+	return types.ExprString(field.Type)
+}
+
+func init() {
+	RegisterRule(StructTagRuleName, func() Rule {
+		return NewStructTagRule()
+	})
+}
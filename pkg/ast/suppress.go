@@ -0,0 +1,253 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ast
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"math"
+	"strings"
+)
+
+type (
+	// suppression is a parsed //lint:ignore or //lint:file-ignore directive
+	suppression struct {
+		// position of the directive comment
+		position token.Position
+
+		// end position of the directive comment
+		end token.Position
+
+		// text of the directive comment
+		text string
+
+		// comment is the directive comment
+		comment *ast.Comment
+
+		// rules suppressed by the directive
+		rules []string
+
+		// lines covered by the directive, inclusive
+		fromLine, toLine int
+
+		// used is true once the directive suppressed a finding
+		used bool
+	}
+)
+
+const (
+	// DirectiveRuleName is the rule name of the findings reported for malformed directives
+	DirectiveRuleName = "lint-directive"
+
+	// UnusedSuppressionRuleName is the rule name of the findings reported for suppressions matching no finding
+	UnusedSuppressionRuleName = "unused-suppression"
+
+	// ignoreDirective suppresses findings on the line of a trailing directive,
+	// or the statement or declaration following a standalone directive
+	ignoreDirective = "//lint:ignore"
+
+	// fileIgnoreDirective suppresses findings in the whole file
+	fileIgnoreDirective = "//lint:file-ignore"
+)
+
+// parseSuppressions parses the suppression directives of the file,
+// malformed directives are returned as findings
+func parseSuppressions(fileSet *token.FileSet, file *ast.File) ([]*suppression, []Finding) {
+	var suppressions []*suppression
+	var malformed []Finding
+
+	var lines *lineIndex
+	for _, group := range file.Comments {
+		for _, comment := range group.List {
+			directive, args, found := strings.Cut(comment.Text, " ")
+			if directive != ignoreDirective && directive != fileIgnoreDirective {
+				continue
+			}
+
+			s := &suppression{
+				position: fileSet.Position(comment.Pos()),
+				end:      fileSet.Position(comment.End()),
+				text:     comment.Text,
+				comment:  comment,
+			}
+
+			fields := strings.Fields(args)
+			if !found || len(fields) < 2 {
+				malformed = append(malformed, s.finding(DirectiveRuleName, SeverityError,
+					fmt.Sprintf("%s needs a rule and a reason: %s <rule>[,<rule>] <reason>", directive, directive)))
+				continue
+			}
+			s.rules = strings.Split(fields[0], ",")
+
+			if directive == fileIgnoreDirective {
+				s.fromLine, s.toLine = 0, math.MaxInt
+			} else {
+				if lines == nil {
+					lines = newLineIndex(fileSet, file)
+				}
+				s.fromLine, s.toLine = lines.coveredLines(s.position)
+			}
+			suppressions = append(suppressions, s)
+		}
+	}
+
+	return suppressions, malformed
+}
+
+// suppress drops the findings matched by a suppression
+func suppress(findings []Finding, suppressions []*suppression) []Finding {
+	if len(suppressions) == 0 {
+		return findings
+	}
+
+	kept := findings[:0]
+	for _, finding := range findings {
+		suppressed := false
+		for _, s := range suppressions {
+			if s.matches(finding) {
+				s.used = true
+				suppressed = true
+			}
+		}
+		if !suppressed {
+			kept = append(kept, finding)
+		}
+	}
+
+	return kept
+}
+
+// unknownSuppressions reports the rules of the suppressions that are neither registered nor run,
+// a misspelled rule would otherwise silently suppress nothing
+func unknownSuppressions(suppressions []*suppression, ran map[string]bool) []Finding {
+	var findings []Finding
+	for _, s := range suppressions {
+		for _, rule := range s.rules {
+			if _, registered := RuleRegistry[rule]; !registered && !ran[rule] {
+				findings = append(findings, s.finding(DirectiveRuleName, SeverityError,
+					fmt.Sprintf("suppression of unknown rule %s", rule)))
+			}
+		}
+	}
+
+	return findings
+}
+
+// unusedSuppressions reports the suppressions that matched no finding,
+// a suppression is only reported if all its rules were run
+func unusedSuppressions(suppressions []*suppression, ran map[string]bool) []Finding {
+	var findings []Finding
+	for _, s := range suppressions {
+		if s.used {
+			continue
+		}
+
+		allRan := true
+		for _, rule := range s.rules {
+			allRan = allRan && ran[rule]
+		}
+		if allRan {
+			findings = append(findings, s.finding(UnusedSuppressionRuleName, SeverityWarning,
+				fmt.Sprintf("suppression of %s matches no finding", strings.Join(s.rules, ","))))
+		}
+	}
+
+	return findings
+}
+
+func (s *suppression) matches(finding Finding) bool {
+	if finding.Position.Line < s.fromLine || finding.Position.Line > s.toLine {
+		return false
+	}
+
+	for _, rule := range s.rules {
+		if rule == finding.Rule {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *suppression) finding(rule string, severity Severity, message string) Finding {
+	return Finding{
+		Rule:     rule,
+		Severity: severity,
+		Message:  message,
+		Subject:  s.text,
+		Position: s.position,
+		End:      s.end,
+		Pos:      s.comment.Pos(),
+		EndPos:   s.comment.End(),
+	}
+}
+
+type (
+	// lineIndex records the lines where nodes start
+	lineIndex struct {
+		// column of the first node starting on a line
+		firstColumn map[int]int
+
+		// last line of the largest node starting on a line
+		lastLine map[int]int
+
+		// maxLine is the last line of the file with a node
+		maxLine int
+	}
+)
+
+func newLineIndex(fileSet *token.FileSet, file *ast.File) *lineIndex {
+	index := &lineIndex{
+		firstColumn: make(map[int]int),
+		lastLine:    make(map[int]int),
+	}
+
+	ast.Inspect(file, func(node ast.Node) bool {
+		switch node.(type) {
+		case nil, *ast.File, *ast.CommentGroup, *ast.Comment:
+			return true
+		}
+
+		start, end := fileSet.Position(node.Pos()), fileSet.Position(node.End())
+		if column, exists := index.firstColumn[start.Line]; !exists || start.Column < column {
+			index.firstColumn[start.Line] = start.Column
+		}
+		index.lastLine[start.Line] = max(index.lastLine[start.Line], end.Line)
+		index.maxLine = max(index.maxLine, end.Line)
+		return true
+	})
+
+	return index
+}
+
+// coveredLines returns the lines covered by an ignore directive at the position,
+// a trailing directive covers its own line, a standalone one covers the node starting on the next line with code
+func (l *lineIndex) coveredLines(position token.Position) (int, int) {
+	if column, exists := l.firstColumn[position.Line]; exists && column < position.Column {
+		return position.Line, position.Line
+	}
+
+	for line := position.Line + 1; line <= l.maxLine; line++ {
+		if _, exists := l.firstColumn[line]; exists {
+			return line, l.lastLine[line]
+		}
+	}
+
+	return position.Line, position.Line
+}
@@ -0,0 +1,149 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ast_test
+
+import (
+	"testing"
+
+	testAssert "github.com/stretchr/testify/assert"
+
+	"github.com/LokiWager/analysis-demo/pkg/ast"
+)
+
+// TestEngine_Suppressions tests the //lint:ignore and //lint:file-ignore directives
+func TestEngine_Suppressions(t *testing.T) {
+	t.Run("Line directives", func(t *testing.T) {
+		assert := testAssert.New(t)
+		src := `package main
+
+func main() {
+	idEqual13xxxx := 1 //lint:ignore ident-length kept for compatibility
+	//lint:ignore ident-length kept for compatibility
+	anotherIdEqua := 2
+	thirdIdEqual1 := 3
+	_, _, _ = idEqual13xxxx, anotherIdEqua, thirdIdEqual1
+}
+`
+		e, err := ast.NewEngine("example.go", src)
+		assert.NoError(err)
+		findings := e.Run(ast.NewIdentLengthRule())
+
+		assert.Len(findings, 4)
+		assert.Equal(7, findings[0].Position.Line)
+		for _, finding := range findings[1:] {
+			assert.Equal(8, finding.Position.Line)
+		}
+	})
+
+	t.Run("Block directive", func(t *testing.T) {
+		assert := testAssert.New(t)
+		src := `package main
+
+// idEqual13xxxx is a function
+//lint:ignore ident-length,nesting-depth generated from the spec
+func idEqual13xxxx() {
+	anotherIdEqua := 2
+	_ = anotherIdEqua
+}
+
+func main() {
+	anotherIdEqua := 2
+	_ = anotherIdEqua
+}
+`
+		e, err := ast.NewEngine("example.go", src)
+		assert.NoError(err)
+		findings := e.Run(ast.NewIdentLengthRule())
+
+		assert.Len(findings, 2)
+		assert.Equal(11, findings[0].Position.Line)
+		assert.Equal(12, findings[1].Position.Line)
+	})
+
+	t.Run("File directive", func(t *testing.T) {
+		assert := testAssert.New(t)
+		src := `//lint:file-ignore ident-length legacy names
+package main
+
+func idEqual13xxxx() {
+}
+`
+		e, err := ast.NewEngine("example.go", src)
+		assert.NoError(err)
+		assert.Empty(e.Run(ast.NewIdentLengthRule(), ast.NewNestingRule()))
+	})
+
+	t.Run("Directive without reason", func(t *testing.T) {
+		assert := testAssert.New(t)
+		src := `package main
+
+func idEqual13xxxx() { //lint:ignore ident-length
+}
+`
+		e, err := ast.NewEngine("example.go", src)
+		assert.NoError(err)
+		findings := e.Run(ast.NewIdentLengthRule())
+
+		assert.Len(findings, 2)
+		assert.Equal(ast.IdentLengthRuleName, findings[0].Rule)
+		assert.Equal(ast.DirectiveRuleName, findings[1].Rule)
+		assert.Equal(ast.SeverityError, findings[1].Severity)
+		assert.Equal(3, findings[1].Position.Line)
+	})
+
+	t.Run("Unused suppressions", func(t *testing.T) {
+		assert := testAssert.New(t)
+		src := `package main
+
+//lint:ignore ident-length no longer needed
+func main() {
+	//lint:ignore nesting-depth rule not run
+	if true {
+	}
+}
+`
+		e, err := ast.NewEngine("example.go", src)
+		assert.NoError(err)
+		assert.Empty(e.Run(ast.NewIdentLengthRule()))
+
+		e.SetReportUnusedSuppressions(true)
+		findings := e.Run(ast.NewIdentLengthRule())
+		assert.Len(findings, 1)
+		assert.Equal(ast.UnusedSuppressionRuleName, findings[0].Rule)
+		assert.Equal(3, findings[0].Position.Line)
+	})
+	t.Run("Unknown rules", func(t *testing.T) {
+		assert := testAssert.New(t)
+		src := `package main
+
+//lint:ignore ident-lenght,nesting-depth misspelled
+func idEqual13xxxx() {
+}
+`
+		e, err := ast.NewEngine("example.go", src)
+		assert.NoError(err)
+		findings := e.Run(ast.NewIdentLengthRule())
+
+		// the misspelled rule suppresses nothing and is reported, the rule not run is known
+		assert.Len(findings, 2)
+		assert.Equal(ast.DirectiveRuleName, findings[0].Rule)
+		assert.Equal(ast.SeverityError, findings[0].Severity)
+		assert.Equal("suppression of unknown rule ident-lenght", findings[0].Message)
+		assert.Equal(ast.IdentLengthRuleName, findings[1].Rule)
+	})
+}
@@ -0,0 +1,51 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cognitivecomplexity
+
+type matrix [][]int
+
+func (m matrix) search(target int) bool { // want `function matrix.search has cognitive complexity 18, more than 15`
+	for i := range m {
+		for j := range m[i] {
+			if m[i][j] == target {
+				return true
+			} else if m[i][j] > target && i > 0 || j > 0 {
+				for k := j; k >= 0; k-- {
+					if m[i][k] == target {
+						return true
+					}
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+func flat(month int) string {
+	switch month {
+	case 1:
+		return "January"
+	case 2:
+		return "February"
+	case 3:
+		return "March"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,64 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package doccomment
+
+type (
+	// Service serves the metrics
+	Service struct{}
+
+	Usage struct{} // want "exported type Usage should have a doc comment"
+
+	// A Checker checks the types
+	Checker interface{}
+
+	// creates the options
+	Options struct{} // want "doc comment of type Options should start with Options"
+
+	service struct{}
+)
+
+// Levels of the checks
+const (
+	LevelError   = "error"
+	LevelWarning = "warning"
+)
+
+const (
+	// MaxDepth is the maximum nesting depth
+	MaxDepth = 4
+
+	MinDepth = 1 // the minimum nesting depth
+)
+
+var DefaultService = &Service{} // want "exported var DefaultService should have a doc comment"
+
+// GetUsage returns the usage
+func (s *Service) GetUsage() Usage {
+	return Usage{}
+}
+
+func (s *Service) Start() {} // want "exported method Service.Start should have a doc comment"
+
+func (s *service) Stop() {}
+
+// register a checker
+func RegisterChecker(checker Checker) {} // want "doc comment of func RegisterChecker should start with RegisterChecker"
+
+func ParseComment(comment string) {} // want "exported func ParseComment should have a doc comment"
+
+func parse() {}
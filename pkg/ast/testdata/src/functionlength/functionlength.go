@@ -0,0 +1,111 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package functionlength
+
+func long() int { // want "function long has 51 statements, more than 50" "function long has 81 lines, more than 80"
+	total := 0
+	total += 0
+	total += 1
+	total += 2
+	total += 3
+	total += 4
+	total += 5
+	total += 6
+	total += 7
+	total += 8
+	total += 9
+	total += 10
+	total += 11
+	total += 12
+	total += 13
+	total += 14
+	total += 15
+	total += 16
+	total += 17
+	total += 18
+	total += 19
+	total += 20
+	total += 21
+	total += 22
+	total += 23
+	total += 24
+	total += 25
+	total += 26
+	total += 27
+	total += 28
+	total += 29
+	total += 30
+	total += 31
+	total += 32
+	total += 33
+	total += 34
+	total += 35
+	total += 36
+	total += 37
+	total += 38
+	total += 39
+	total += 40
+	total += 41
+	total += 42
+	total += 43
+	total += 44
+	total += 45
+	total += 46
+	total += 47
+	total += 48
+	// keeps the body above 80 lines
+	// keeps the body above 80 lines
+	// keeps the body above 80 lines
+	// keeps the body above 80 lines
+	// keeps the body above 80 lines
+	// keeps the body above 80 lines
+	// keeps the body above 80 lines
+	// keeps the body above 80 lines
+	// keeps the body above 80 lines
+	// keeps the body above 80 lines
+	// keeps the body above 80 lines
+	// keeps the body above 80 lines
+	// keeps the body above 80 lines
+	// keeps the body above 80 lines
+	// keeps the body above 80 lines
+	// keeps the body above 80 lines
+	// keeps the body above 80 lines
+	// keeps the body above 80 lines
+	// keeps the body above 80 lines
+	// keeps the body above 80 lines
+	// keeps the body above 80 lines
+	// keeps the body above 80 lines
+	// keeps the body above 80 lines
+	// keeps the body above 80 lines
+	// keeps the body above 80 lines
+	// keeps the body above 80 lines
+	// keeps the body above 80 lines
+	// keeps the body above 80 lines
+	// keeps the body above 80 lines
+	// keeps the body above 80 lines
+	return total
+}
+
+func short() int {
+	total := 0
+	for i := 0; i < 10; i++ {
+		total += i
+	}
+
+	return total
+}
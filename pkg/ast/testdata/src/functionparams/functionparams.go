@@ -0,0 +1,31 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package functionparams
+
+type server struct{}
+
+func (s *server) listen(host string, port, backlog int, reuse, keepAlive, tls bool) { // want "function server.listen has 6 parameters, more than 5"
+}
+
+func split(path string) (dir, base, ext string, err error) { // want "function split has 4 results, more than 3"
+	return
+}
+
+func connect(host string, port int, _ bool, opts ...string) (int, error) {
+	return 0, nil
+}
@@ -0,0 +1,6 @@
+//go:build go1.18
+
+// missing the license header
+package licenseheader // want "missing license header"
+
+func missing() {}
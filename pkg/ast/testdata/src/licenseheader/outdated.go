@@ -0,0 +1,6 @@
+// Copyright (c) 2020-2023, LokiWager
+// Licensed under the MIT License. // want `license header does not match the template, line 2 should be "All rights reserved."`
+
+package licenseheader
+
+func outdated() {}
@@ -0,0 +1,76 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package magicliteral
+
+import (
+	"fmt"
+	"time"
+)
+
+const timeout = 30 * time.Second
+
+type (
+	endpoint struct {
+		Host string `json:"host"`
+		Port int    `json:"port"`
+	}
+
+	request struct{}
+)
+
+var portBase = 32001 // want `magic number 32001, extract it to a named constant`
+
+var digest [32]byte
+
+func (r *request) SetQueryParam(key, value string) *request {
+	return r
+}
+
+// dumpTraceFile requests a trace of the process
+func dumpTraceFile(r *request) {
+	r.SetQueryParam("seconds", "") // want `magic string "seconds", extract it to a named constant`
+}
+
+func next(i int) int {
+	if i < 0 {
+		return -1
+	}
+	fmt.Printf("next of %d\n", i)
+	return i + 1
+}
+
+func retries(backoff bool) int {
+	if backoff {
+		return -3 // want `magic number -3, extract it to a named constant`
+	}
+	return 5 // want `magic number 5, extract it to a named constant`
+}
+
+func (r *request) Doc() string {
+	return "requests a trace"
+}
+
+func (r *request) Reportf(format string, args ...interface{}) {}
+
+func (r *request) report(n int) {
+	r.Reportf("%d samples", n)
+}
+
+func defaults() endpoint {
+	return endpoint{Host: "localhost"} // want `magic string "localhost", extract it to a named constant`
+}
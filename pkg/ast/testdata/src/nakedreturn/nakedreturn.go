@@ -0,0 +1,51 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package nakedreturn
+
+func parse(s string) (n int, err error) {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return 0, nil
+		}
+		n = n*10 + int(c-'0')
+	}
+	return // want "naked return in function parse with 7 lines, more than 5"
+}
+
+func short(s string) (n int) {
+	n = len(s)
+	return
+}
+
+func literal() func() int {
+	return func() (n int) {
+		n = 1
+		return
+	}
+}
+
+func unnamed() int {
+	f := func() {
+		return
+	}
+	f()
+	f()
+	f()
+	f()
+	return 0
+}
@@ -0,0 +1,55 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package naming
+
+const (
+	MAX_RETRIES = 3 // want "const name MAX_RETRIES uses underscores, use MixedCaps: MaxRetries"
+	x           = 1 // want "const name x is shorter than 2 characters"
+)
+
+type (
+	userId int // want "type name userId should be userID"
+
+	HttpClient interface { // want "type name HttpClient should be HTTPClient"
+		GetUrl() string // want "func name GetUrl should be GetURL"
+	}
+
+	server struct{}
+)
+
+var default_timeout = 10 // want "var name default_timeout uses underscores, use MixedCaps: defaultTimeout"
+
+func (client *server) ServeHTTP() { // want "receiver name client is longer than 4 characters"
+}
+
+func apiURL(id userId) string {
+	for index_value := 0; index_value < 3; index_value++ { // want "loop var name index_value uses underscores, use MixedCaps: indexValue"
+	}
+
+	jsonId := "id" // want "var name jsonId should be jsonID"
+	return jsonId
+}
+
+func shortScopes(values []int) int {
+	total := 0
+	for i, v := range values {
+		total += i * v
+	}
+
+	return total
+}
@@ -0,0 +1,88 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package nestingdepth
+
+func nested(n int) {
+	for i := 0; i < n; i++ {
+		if i > 1 {
+			switch i {
+			case 2:
+				select {
+				default:
+					if i%2 == 0 { // want `control flow nested 5 levels deep, more than 4: func nested > for \(line 21\) > if \(line 22\) > switch \(line 23\) > case \(line 24\) > select \(line 25\) > default \(line 26\) > if \(line 27\)`
+						return
+					}
+				}
+			}
+		}
+	}
+}
+
+func innermost(values []any, ch chan int) {
+	for range values {
+		for _, value := range values {
+			switch v := value.(type) {
+			case int:
+				select {
+				case <-ch:
+					if v > 0 {
+						if v > 1 { // want `control flow nested 6 levels deep, more than 4: func innermost > range \(line 37\) > range \(line 38\) > type switch \(line 39\) > case \(line 40\) > select \(line 41\) > case \(line 42\) > if \(line 43\) > if \(line 44\)`
+							return
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func closures(n int) func() {
+	for i := 0; i < n; i++ {
+		if i > 1 {
+			switch i {
+			case 2:
+				return func() {
+					for {
+						if n > 0 {
+							return
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func elseIf(n int) {
+	for i := 0; i < n; i++ {
+		if i == 1 {
+			return
+		} else if i == 2 {
+			return
+		} else if i == 3 {
+			switch {
+			default:
+				if i > 3 {
+					return
+				}
+			}
+		}
+	}
+}
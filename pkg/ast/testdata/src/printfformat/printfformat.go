@@ -0,0 +1,64 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package printfformat
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+type logger struct{}
+
+func (l *logger) Infof(format string, args ...interface{}) {
+	fmt.Printf(format, args...)
+}
+
+func (l *logger) Info(msg string, args ...interface{}) {
+}
+
+type point struct {
+	x, y int
+}
+
+func initSystem(l *logger, err error) {
+	log.Printf(err.Error()) // want `non-constant format string in call to log.Printf`
+	msg := "ready"
+	l.Infof(msg) // want `non-constant format string in call to l.Infof`
+	l.Info(msg)
+
+	l.Infof("listening on %d", "8080") // want `l.Infof format %d has argument "8080" of wrong type string`
+	l.Infof("%s took %v", "start")     // want `l.Infof format "%s took %v" needs 2 arguments but the call has 1`
+	l.Infof("done", 1)                 // want `l.Infof format "done" needs 0 arguments but the call has 1`
+	l.Infof("%d%%", 50)
+	l.Infof("%O", 8)
+	l.Infof("%O", "8")     // want `l.Infof format %O has argument "8" of wrong type string`
+	l.Infof("%y", 1)       // want `l.Infof format "%y" has unknown verb y in directive %y`
+	l.Infof("%*d", "8", 1) // want `l.Infof format %\*d uses non-int "8" as width or precision`
+	l.Infof("%[2]d %[1]s", "a", 1)
+	l.Infof("%[3]d", 1, 2) // want `l.Infof format "%\[3\]d" needs 3 arguments but the call has 2`
+	l.Infof("%s %v %d", err, time.Second, time.Second)
+	p := point{1, 2}
+	l.Infof("%d %d", p, &p)
+	l.Infof("%s", p) // want `l.Infof format %s has argument p of wrong type printfformat.point`
+	l.Infof("%t %p %x %q", true, &msg, []byte(msg), 'r')
+	_ = fmt.Errorf("wrap: %w", err)
+	_ = fmt.Errorf("wrap: %w", msg) // want `fmt.Errorf format %w has argument msg of wrong type string`
+	_ = fmt.Sprintf("%s", errors.New(msg))
+}
@@ -0,0 +1,27 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package receiverconsistency
+
+func (c counter) Double() int { // want "method counter.Double has a value receiver, the other methods of counter have pointer receivers"
+	return 2 * c.n
+}
+
+func (pt *point) Scale(k int) { // want "method point.Scale has a pointer receiver, the other methods of point have value receivers" "receiver name pt of method point.Scale differs from p used by the other methods of point"
+	pt.x *= k
+	pt.y *= k
+}
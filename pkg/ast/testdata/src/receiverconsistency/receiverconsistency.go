@@ -0,0 +1,54 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package receiverconsistency
+
+type counter struct {
+	n int
+}
+
+func (c *counter) Inc() {
+	c.n++
+}
+
+func (c *counter) Reset() {
+	c.n = 0
+}
+
+func (c counter) Value() int { // want "method counter.Value has a value receiver, the other methods of counter have pointer receivers"
+	return c.n
+}
+
+func (cnt *counter) Add(n int) { // want "receiver name cnt of method counter.Add differs from c used by the other methods of counter"
+	cnt.n += n
+}
+
+type point struct {
+	x, y int
+}
+
+func (p point) X() int {
+	return p.x
+}
+
+func (p point) Y() int {
+	return p.y
+}
+
+func (point) String() string {
+	return "point"
+}
@@ -0,0 +1,46 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package structtag
+
+type (
+	ServiceConfig struct {
+		ProcessID   int  `yaml:"processID" json:"processID"`
+		Persist     bool `yaml:"persist" json:"persist"`
+		ServicePort int  // want `field ServicePort of ServiceConfig has no json, yaml tag, its sibling fields have one`
+		state       int
+	}
+
+	ProcessInfo struct {
+		Name     string `json:"name"`
+		NumFDs   int32  `json:"numFDs"`
+		UserName string `json:"userName"`
+		Pid      int    `json:"name"`             // want `struct ProcessInfo has duplicate json name name, also used by field Name`
+		Ppid     int32  `json:"ppid",bson:"ppid"` // want `struct field tag .* of Ppid: key:"value" pairs not separated by spaces`
+		Cwd      string `json:"cwd" json:"pwd"`   // want `struct field tag .* of Cwd: duplicate struct tag key json`
+		Exe      string `json:exe`                // want `struct field tag .* of Exe: bad syntax for struct tag value`
+		Ignored  string `json:"-"`
+		Dash     string `json:"-,"`
+		Extra    string `json:",omitempty"`
+	}
+
+	EMAValue struct {
+		CPUPercent    float64 `json:"cpu_percent"`    // want `json name cpu_percent is not camelCase like the other names of the package`
+		MemoryPercent float64 `json:"memory_Percent"` // want `json name memory_Percent is not camelCase like the other names of the package`
+		Connections   float64 `json:"connections"`
+	}
+)
@@ -0,0 +1,24 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package structtag
+
+// Usage has a single snake_case name, the names of the other file of the package are camelCase
+type Usage struct {
+	CPUPercent  float64 `json:"cpu_percent"` // want `json name cpu_percent is not camelCase like the other names of the package`
+	Connections int     `json:"connections"`
+}
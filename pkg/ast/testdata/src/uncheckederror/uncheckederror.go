@@ -0,0 +1,66 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package uncheckederror
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+)
+
+type logFile struct {
+	buf bytes.Buffer
+}
+
+func (lf *logFile) flush() error {
+	return nil
+}
+
+func (lf *logFile) size() (int, error) {
+	return lf.buf.Len(), nil
+}
+
+func (lf *logFile) run() {
+	lf.flush() // want `error result of lf.flush is not checked`
+
+	// the buffer is dropped on exit anyway
+	_ = lf.flush()
+	n, _ := lf.size() // the size is only logged
+	fmt.Println(n)
+
+	lf.buf.WriteString("done")
+	fmt.Fprintln(os.Stderr, "done")
+	sha256.New().Write(lf.buf.Bytes())
+	fnv.New64a().Write(lf.buf.Bytes())
+	fnv.New32().Write(lf.buf.Bytes())
+	os.Stdout.Write(lf.buf.Bytes()) // want `error result of os.Stdout.Write is not checked`
+	_ = error(nil)
+	var err = errors.New("closed")
+	_ = err
+	defer lf.flush()
+
+	if err := lf.flush(); err != nil {
+		return
+	}
+}
+
+// the lock may not exist
+var _ = os.Remove("lock")
@@ -0,0 +1,256 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ast
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+type (
+	// UncheckedErrorRule reports error results discarded by a bare call statement
+	// or assigned to _ without a comment explaining why, it requires type information
+	UncheckedErrorRule struct {
+		// exclude are the full names of the functions whose errors may be discarded, like fmt.Println
+		exclude map[string]bool
+	}
+)
+
+const (
+	// UncheckedErrorRuleName is the name of the UncheckedErrorRule
+	UncheckedErrorRuleName = "unchecked-error"
+)
+
+// defaultUncheckedErrorExclude are the functions whose errors are discarded by convention,
+// they only fail when the underlying writer fails or never fail at all
+var defaultUncheckedErrorExclude = []string{
+	"fmt.Print", "fmt.Printf", "fmt.Println",
+	"fmt.Fprint", "fmt.Fprintf", "fmt.Fprintln",
+	"(*bytes.Buffer).Write", "(*bytes.Buffer).WriteByte", "(*bytes.Buffer).WriteRune", "(*bytes.Buffer).WriteString",
+	"(*strings.Builder).Write", "(*strings.Builder).WriteByte", "(*strings.Builder).WriteRune", "(*strings.Builder).WriteString",
+	"(hash.Hash).Write", "(hash.Hash32).Write", "(hash.Hash64).Write", "math/rand.Read",
+}
+
+// errorType is the predeclared error interface
+var errorType = types.Universe.Lookup("error").Type()
+
+// NewUncheckedErrorRule creates a new UncheckedErrorRule instance with the default exclusion list
+func NewUncheckedErrorRule() *UncheckedErrorRule {
+	r := &UncheckedErrorRule{}
+	r.setExclude(defaultUncheckedErrorExclude)
+
+	return r
+}
+
+// Name returns the name of the rule
+func (r *UncheckedErrorRule) Name() string {
+	return UncheckedErrorRuleName
+}
+
+// Doc returns the documentation of the rule
+func (r *UncheckedErrorRule) Doc() string {
+	return "reports error results discarded by call statements or assigned to _ without a justification comment"
+}
+
+// Severity returns the default severity of the rule
+func (r *UncheckedErrorRule) Severity() Severity {
+	return SeverityWarning
+}
+
+// RequiresTypes reports that the rule needs the types of the called functions
+func (r *UncheckedErrorRule) RequiresTypes() bool {
+	return true
+}
+
+// Configure sets the exclude parameter, the full names of the functions whose errors may be discarded,
+// e.g. fmt.Println or (*bytes.Buffer).Write
+func (r *UncheckedErrorRule) Configure(params Params) error {
+	if err := params.Check("exclude"); err != nil {
+		return err
+	}
+
+	exclude, err := params.Strings("exclude", defaultUncheckedErrorExclude)
+	if err != nil {
+		return err
+	}
+	r.setExclude(exclude)
+
+	return nil
+}
+
+func (r *UncheckedErrorRule) setExclude(names []string) {
+	r.exclude = make(map[string]bool, len(names))
+	for _, name := range names {
+		r.exclude[name] = true
+	}
+}
+
+// Visit checks the call statements and the assignments of calls to _
+func (r *UncheckedErrorRule) Visit(ctx *Context, node ast.Node) {
+	switch stmt := node.(type) {
+	case *ast.ExprStmt:
+		call, ok := ast.Unparen(stmt.X).(*ast.CallExpr)
+		if ok && r.checked(ctx, call) && len(r.errorResults(ctx, call)) > 0 {
+			ctx.Reportf(call, "error result of %s is not checked", types.ExprString(call.Fun))
+		}
+	case *ast.AssignStmt:
+		r.checkBlank(ctx, stmt, stmt.Lhs, stmt.Rhs)
+	case *ast.ValueSpec:
+		lhs := make([]ast.Expr, 0, len(stmt.Names))
+		for _, name := range stmt.Names {
+			lhs = append(lhs, name)
+		}
+		r.checkBlank(ctx, stmt, lhs, stmt.Values)
+	}
+}
+
+// checkBlank reports the error results of calls assigned to _ when the statement has no comment
+func (r *UncheckedErrorRule) checkBlank(ctx *Context, stmt ast.Node, lhs, rhs []ast.Expr) {
+	if hasJustification(ctx, stmt) {
+		return
+	}
+
+	for i, value := range rhs {
+		call, ok := ast.Unparen(value).(*ast.CallExpr)
+		if !ok || !r.checked(ctx, call) {
+			continue
+		}
+
+		// a call with several results is the only value of the assignment
+		targets := lhs
+		if len(rhs) > 1 {
+			targets = lhs[i : i+1]
+		}
+		for _, index := range r.errorResults(ctx, call) {
+			if index < len(targets) && isBlank(targets[index]) {
+				ctx.Reportf(targets[index], "error result of %s is assigned to _ without a comment explaining why",
+					types.ExprString(call.Fun))
+			}
+		}
+	}
+}
+
+// checked reports whether the errors of the called function must be checked,
+// a method is excluded by the name of its declaration or of the static type of its receiver,
+// e.g. (hash.Hash).Write for the Write method hash.Hash embeds from io.Writer
+func (r *UncheckedErrorRule) checked(ctx *Context, call *ast.CallExpr) bool {
+	fn, ok := typeutil.Callee(ctx.TypesInfo, call).(*types.Func)
+	if !ok {
+		return true
+	}
+	if r.exclude[fn.FullName()] {
+		return false
+	}
+
+	selector, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+	if sel := ctx.TypesInfo.Selections[selector]; ok && sel != nil {
+		return !r.exclude[fmt.Sprintf("(%s).%s", types.TypeString(sel.Recv(), nil), fn.Name())]
+	}
+
+	return true
+}
+
+// errorResults returns the indexes of the error results of the call
+func (r *UncheckedErrorRule) errorResults(ctx *Context, call *ast.CallExpr) []int {
+	var indexes []int
+	switch result := ctx.TypesInfo.TypeOf(call).(type) {
+	case nil:
+	case *types.Tuple:
+		for i := 0; i < result.Len(); i++ {
+			if types.Identical(result.At(i).Type(), errorType) {
+				indexes = append(indexes, i)
+			}
+		}
+	default:
+		// a conversion to error is not a call returning one
+		if tv, ok := ctx.TypesInfo.Types[call.Fun]; ok && tv.IsType() {
+			return nil
+		}
+		if types.Identical(result, errorType) {
+			indexes = append(indexes, 0)
+		}
+	}
+
+	return indexes
+}
+
+// hasJustification reports whether a comment ends on the line of the statement or on its own line above it,
+// the trailing comment of the line above belongs to that line
+func hasJustification(ctx *Context, stmt ast.Node) bool {
+	line := ctx.FileSet.Position(stmt.Pos()).Line
+	end := ctx.FileSet.Position(stmt.End()).Line
+	for _, group := range ctx.File.Comments {
+		commentLine := ctx.FileSet.Position(group.End()).Line
+		if commentLine < line-1 || commentLine > end || isDirective(group) {
+			continue
+		}
+		if commentLine >= line || !isTrailingComment(ctx, group.List[len(group.List)-1]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isTrailingComment reports whether code precedes the comment on its line
+func isTrailingComment(ctx *Context, comment *ast.Comment) bool {
+	file := ctx.FileSet.File(comment.Pos())
+	lineStart := file.LineStart(file.Line(comment.Pos()))
+
+	trailing := false
+	ast.Inspect(ctx.File, func(node ast.Node) bool {
+		if trailing || node == nil || node.Pos() >= comment.Pos() || node.End() < lineStart {
+			return false
+		}
+		if _, ok := node.(*ast.CommentGroup); ok {
+			return false
+		}
+		// a node ending on the line before the comment is code preceding it
+		trailing = node.End() <= comment.Pos()
+		return !trailing
+	})
+
+	return trailing
+}
+
+// isDirective reports whether the comment group only holds directives like //lint:ignore or //go:generate
+func isDirective(group *ast.CommentGroup) bool {
+	for _, comment := range group.List {
+		if !strings.HasPrefix(comment.Text, "//lint:") && !strings.HasPrefix(comment.Text, "//go:") &&
+			!strings.HasPrefix(comment.Text, "//nolint") {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isBlank(expr ast.Expr) bool {
+	ident, ok := expr.(*ast.Ident)
+	return ok && ident.Name == "_"
+}
+
+func init() {
+	RegisterRule(UncheckedErrorRuleName, func() Rule {
+		return NewUncheckedErrorRule()
+	})
+}
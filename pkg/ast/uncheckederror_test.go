@@ -0,0 +1,105 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ast_test
+
+import (
+	goast "go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+
+	testAssert "github.com/stretchr/testify/assert"
+
+	"github.com/LokiWager/analysis-demo/pkg/ast"
+)
+
+// typeCheck parses and type-checks the source of a single file package
+func typeCheck(t *testing.T, name, src string) (*token.FileSet, *goast.File, *types.Info) {
+	fileSet := token.NewFileSet()
+	file, err := parser.ParseFile(fileSet, name, src, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	info := &types.Info{
+		Types:      map[goast.Expr]types.TypeAndValue{},
+		Defs:       map[*goast.Ident]types.Object{},
+		Uses:       map[*goast.Ident]types.Object{},
+		Selections: map[*goast.SelectorExpr]*types.Selection{},
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fileSet, "source", nil)}
+	if _, err := conf.Check(file.Name.Name, fileSet, []*goast.File{file}, info); err != nil {
+		t.Fatalf("type check failed: %v", err)
+	}
+
+	return fileSet, file, info
+}
+
+// TestUncheckedErrorRule tests the errors assigned to _, the analyzer test data cannot have
+// want comments on them since any comment justifies the assignment
+func TestUncheckedErrorRule(t *testing.T) {
+	assert := testAssert.New(t)
+	src := `
+package mongodbtool
+
+import (
+	"context"
+	"os"
+)
+
+type client struct{}
+
+func (c *client) Disconnect(ctx context.Context) error {
+	return nil
+}
+
+func CloseMDB(c *client) {
+	_ = c.Disconnect(nil)
+	name, _ := os.Hostname()
+	_, _ = name, os.Remove(name)
+
+	// the client is closed on exit anyway
+	_ = c.Disconnect(nil)
+	//lint:ignore naming not a justification
+	_ = c.Disconnect(nil)
+	name = os.Getenv("HOST") // the host is optional
+	_ = c.Disconnect(nil)
+}
+`
+
+	fileSet, file, info := typeCheck(t, "mdbtool.go", src)
+	e := ast.NewEngineFromFile(fileSet, file)
+	e.SetTypesInfo(info)
+	var messages []string
+	for _, finding := range e.Run(ast.NewUncheckedErrorRule()) {
+		messages = append(messages, finding.Message)
+	}
+	assert.Equal([]string{
+		"error result of c.Disconnect is assigned to _ without a comment explaining why",
+		"error result of os.Hostname is assigned to _ without a comment explaining why",
+		"error result of os.Remove is assigned to _ without a comment explaining why",
+		"error result of c.Disconnect is assigned to _ without a comment explaining why",
+		"error result of c.Disconnect is assigned to _ without a comment explaining why",
+	}, messages)
+
+	// the rule is skipped without type information
+	e = ast.NewEngineFromFile(fileSet, file)
+	assert.Empty(e.Run(ast.NewUncheckedErrorRule()))
+}
@@ -18,20 +18,26 @@
 package cfg
 
 import (
+	"errors"
 	"fmt"
 	"go/ast"
 	"go/constant"
 	"go/parser"
 	"go/token"
 	"go/types"
-	"log"
 
 	"github.com/sirupsen/logrus"
 	"golang.org/x/tools/go/packages"
 	"golang.org/x/tools/go/ssa"
 	"golang.org/x/tools/go/ssa/ssautil"
+
+	lintast "github.com/LokiWager/analysis-demo/pkg/ast"
+	"github.com/LokiWager/analysis-demo/pkg/config"
 )
 
+// defaultFunction is the function analyzed when the project configuration lists none
+const defaultFunction = "example"
+
 type (
 	// Engine is the analysis engine
 	Engine struct {
@@ -52,6 +58,12 @@ type (
 
 		// result of the analysis
 		result []analysisResult
+
+		// findings of the syntax and type errors of the source code
+		findings []lintast.Finding
+
+		// functions to analyze, from the parity section of the project configuration
+		functions []string
 	}
 
 	analysisResult struct {
@@ -64,19 +76,37 @@ type (
 // path is the path of the source code, if not exists, pass an empty string
 // src is the source code, if not exists, pass nil
 // path and src must not be nil at the same time
-func NewEngine(path string, fileName string, src any) *Engine {
+// syntax errors do not fail, they are reported by Findings, an error is only returned if the source code cannot be read
+// the functions to analyze are read from the project configuration found from path upwards, example by default
+func NewEngine(path string, fileName string, src any) (*Engine, error) {
 	fileSet := token.NewFileSet()
 	file, err := parser.ParseFile(fileSet, fmt.Sprintf("%s/%s", path, fileName), src, parser.AllErrors)
-	if err != nil {
-		logrus.Errorf("parse file %s failed: %v", path, err)
-		panic(err)
+	if file == nil {
+		return nil, fmt.Errorf("parse file %s failed: %w", path, err)
 	}
 
-	return &Engine{
-		fileSet: fileSet,
-		file:    file,
-		pkgPath: path,
+	project, configErr := config.Find(path)
+	if configErr != nil {
+		return nil, configErr
+	}
+
+	functions := project.Parity.Functions
+	if len(functions) == 0 {
+		functions = []string{defaultFunction}
 	}
+
+	return &Engine{
+		fileSet:   fileSet,
+		file:      file,
+		pkgPath:   path,
+		findings:  lintast.ErrorFindings(err),
+		functions: functions,
+	}, nil
+}
+
+// Findings returns the syntax and type errors of the source code as findings
+func (e *Engine) Findings() []lintast.Finding {
+	return e.findings
 }
 
 // GetPackage returns the package name of the source code
@@ -85,17 +115,28 @@ func (e *Engine) GetPackage() string {
 }
 
 // CreateProgram creates the program of the source code
+// syntax and type errors do not fail, they are reported by Findings and the parity analysis is skipped,
+// since the program of an ill-typed package cannot be built
 func (e *Engine) CreateProgram() error {
-	pkg := types.NewPackage(e.pkgPath, e.GetPackage())
-	conf := types.Config{Importer: nil}
+	var typeErrors []error
+	conf := types.Config{
+		Importer: nil,
+		Error: func(err error) {
+			typeErrors = append(typeErrors, err)
+		},
+	}
 	info := &types.Info{
 		Types: make(map[ast.Expr]types.TypeAndValue),
 	}
 	e.conf = &conf
-	pkg, err := e.conf.Check(e.pkgPath, e.fileSet, []*ast.File{e.file}, info)
-	if err != nil {
-		logrus.Errorf("check file %s failed: %v", e.pkgPath, err)
-		return err
+	// the type errors are collected by the Error function of the config
+	pkg, _ := e.conf.Check(e.pkgPath, e.fileSet, []*ast.File{e.file}, info)
+	if len(typeErrors) > 0 {
+		e.findings = append(e.findings, lintast.ErrorFindings(errors.Join(typeErrors...))...)
+	}
+	if len(e.findings) > 0 {
+		logrus.Warnf("check file %s failed with %d errors, skip the analysis", e.pkgPath, len(e.findings))
+		return nil
 	}
 	e.pkgPath = pkg.Path()
 
@@ -105,9 +146,21 @@ func (e *Engine) CreateProgram() error {
 	}
 	initial, err := packages.Load(&cfg, e.pkgPath)
 	if err != nil {
-		log.Fatalf("load packages failed: %v", err)
-		return err
+		return fmt.Errorf("load packages failed: %w", err)
 	}
+
+	var loadErrors []error
+	packages.Visit(initial, nil, func(p *packages.Package) {
+		for _, err := range p.Errors {
+			loadErrors = append(loadErrors, err)
+		}
+	})
+	if len(loadErrors) > 0 {
+		e.findings = append(e.findings, lintast.ErrorFindings(errors.Join(loadErrors...))...)
+		logrus.Warnf("load package %s failed with %d errors, skip the analysis", e.pkgPath, len(loadErrors))
+		return nil
+	}
+
 	prog, _ := ssautil.AllPackages(initial, ssa.SanityCheckFunctions)
 	prog.Build()
 	e.prog = prog
@@ -116,11 +169,10 @@ func (e *Engine) CreateProgram() error {
 		if progPackage.Pkg.Name() != pkg.Name() {
 			continue
 		}
-		for _, member := range progPackage.Members {
-			if fn, ok := member.(*ssa.Function); ok {
-				if fn.Name() == "example" {
-					e.analyzeEvenOdd(fn)
-				}
+		// analyze the functions in the configured order, members are a map
+		for _, name := range e.functions {
+			if fn, ok := progPackage.Members[name].(*ssa.Function); ok {
+				e.analyzeEvenOdd(fn)
 			}
 		}
 	}
@@ -18,20 +18,25 @@
 package cfg
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	testAssert "github.com/stretchr/testify/assert"
+
+	lintast "github.com/LokiWager/analysis-demo/pkg/ast"
+	"github.com/LokiWager/analysis-demo/pkg/config"
 )
 
 func TestEngine_ForIfControl(t *testing.T) {
 	t.Run("Test Engine for if control", func(t *testing.T) {
 		assert := testAssert.New(t)
-		engine := NewEngine("../../tests/control_if", "example.go", nil)
-		if engine == nil {
-			t.Errorf("new engine failed")
+		engine, err := NewEngine("../../tests/control_if", "example.go", nil)
+		if err != nil {
+			t.Errorf("new engine failed: %v", err)
 			return
 		}
-		err := engine.CreateProgram()
+		err = engine.CreateProgram()
 		if err != nil {
 			t.Errorf("create program failed: %v", err)
 		}
@@ -47,12 +52,12 @@ func TestEngine_ForIfControl(t *testing.T) {
 func TestEngine_ForForControl(t *testing.T) {
 	t.Run("Test Engine for for control", func(t *testing.T) {
 		assert := testAssert.New(t)
-		engine := NewEngine("../../tests/control_for", "example.go", nil)
-		if engine == nil {
-			t.Errorf("new engine failed")
+		engine, err := NewEngine("../../tests/control_for", "example.go", nil)
+		if err != nil {
+			t.Errorf("new engine failed: %v", err)
 			return
 		}
-		err := engine.CreateProgram()
+		err = engine.CreateProgram()
 		if err != nil {
 			t.Errorf("create program failed: %v", err)
 		}
@@ -68,12 +73,12 @@ func TestEngine_ForForControl(t *testing.T) {
 func TestEngine_ForFuncCall(t *testing.T) {
 	t.Run("Test Engine for function call", func(t *testing.T) {
 		assert := testAssert.New(t)
-		engine := NewEngine("../../tests/func_call", "example.go", nil)
-		if engine == nil {
-			t.Errorf("new engine failed")
+		engine, err := NewEngine("../../tests/func_call", "example.go", nil)
+		if err != nil {
+			t.Errorf("new engine failed: %v", err)
 			return
 		}
-		err := engine.CreateProgram()
+		err = engine.CreateProgram()
 		if err != nil {
 			t.Errorf("create program failed: %v", err)
 		}
@@ -91,3 +96,73 @@ func TestEngine_ForFuncCall(t *testing.T) {
 		assert.Equal("⊤", engine.result[1].Parity)
 	})
 }
+
+func TestEngine_SyntaxError(t *testing.T) {
+	t.Run("Test Engine for syntax errors", func(t *testing.T) {
+		assert := testAssert.New(t)
+		src := `package broken
+
+func example(x int) {
+	if x == 0 {
+		x = x *
+	}
+}
+`
+		engine, err := NewEngine("../../tests/broken", "example.go", src)
+		assert.NoError(err)
+		assert.NoError(engine.CreateProgram())
+
+		findings := engine.Findings()
+		assert.NotEmpty(findings)
+		assert.Equal(lintast.SyntaxErrorRuleName, findings[0].Rule)
+		assert.Equal(6, findings[0].Position.Line)
+		assert.Empty(engine.result)
+	})
+}
+
+func TestEngine_TypeError(t *testing.T) {
+	t.Run("Test Engine for type errors", func(t *testing.T) {
+		assert := testAssert.New(t)
+		src := `package broken
+
+func example(x int) {
+	y := "a" + x
+	z := undefined
+}
+`
+		engine, err := NewEngine("../../tests/broken", "example.go", src)
+		assert.NoError(err)
+		assert.NoError(engine.CreateProgram())
+
+		findings := engine.Findings()
+		assert.Len(findings, 4)
+		for _, finding := range findings {
+			assert.Equal(lintast.TypeErrorRuleName, finding.Rule)
+			assert.Equal(lintast.SeverityError, finding.Severity)
+		}
+		assert.Equal(4, findings[0].Position.Line)
+		assert.Empty(engine.result)
+	})
+}
+
+func TestEngine_MissingFile(t *testing.T) {
+	_, err := NewEngine("../../tests/missing", "example.go", nil)
+	testAssert.Error(t, err)
+}
+
+func TestEngine_Config(t *testing.T) {
+	t.Run("Test Engine reads the functions to analyze from the project configuration", func(t *testing.T) {
+		assert := testAssert.New(t)
+		dir := t.TempDir()
+		err := os.WriteFile(filepath.Join(dir, config.FileName), []byte("parity:\n  functions: [first, second]\n"), 0o644)
+		assert.NoError(err)
+
+		engine, err := NewEngine(dir, "example.go", "package example\n")
+		assert.NoError(err)
+		assert.Equal([]string{"first", "second"}, engine.functions)
+
+		engine, err = NewEngine("../../tests/control_if", "example.go", nil)
+		assert.NoError(err)
+		assert.Equal([]string{defaultFunction}, engine.functions)
+	})
+}
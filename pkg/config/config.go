@@ -0,0 +1,409 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type (
+	// Config is the project configuration of the analyzers, read from a .analysis.yaml file
+	Config struct {
+		// Path is the file the configuration was read from, empty for the default configuration
+		Path string `yaml:"-"`
+
+		// Format is the default output format of cmd/lint
+		Format string `yaml:"format"`
+
+		// Include are the globs of the files to analyze relative to the configuration file, all files if empty
+		Include []string `yaml:"include"`
+
+		// Exclude are the globs of the files not to analyze relative to the configuration file
+		Exclude []string `yaml:"exclude"`
+
+		// Rules configures the lint rules
+		Rules Rules `yaml:"rules"`
+
+		// Overrides configure the lint rules of directories, later overrides take precedence
+		Overrides []Override `yaml:"overrides"`
+
+		// Checker configures the @check annotation analyzer
+		Checker Checker `yaml:"checker"`
+
+		// Parity configures the even/odd analysis of the cfg engine
+		Parity Parity `yaml:"parity"`
+
+		// DocCoverage configures the doc comment coverage report
+		DocCoverage DocCoverage `yaml:"docCoverage"`
+
+		// Clones configures the clone detection
+		Clones Clones `yaml:"clones"`
+	}
+
+	// Rules configures the lint rules
+	Rules struct {
+		// Enable are the rules to run, the default rules of the registry if empty
+		Enable []string `yaml:"enable"`
+
+		// Disable are the rules to skip
+		Disable []string `yaml:"disable"`
+
+		// Severity overrides the severities of the rules, keyed by rule name
+		Severity map[string]string `yaml:"severity"`
+
+		// Params are the parameters of the rules keyed by rule name,
+		// nested maps are flattened into dotted keys, e.g. min-length.var
+		Params map[string]map[string]any `yaml:"params"`
+	}
+
+	// Override configures the lint rules of the files matching a glob
+	Override struct {
+		// Path is the glob of the files or directories relative to the configuration file
+		Path string `yaml:"path"`
+
+		// Rules are merged into the rules of the matching files
+		Rules Rules `yaml:"rules"`
+	}
+
+	// Checker configures the @check annotation analyzer
+	Checker struct {
+		// Disable are the checkers to skip, e.g. Range
+		Disable []string `yaml:"disable"`
+	}
+
+	// Parity configures the even/odd analysis of the cfg engine
+	Parity struct {
+		// Functions are the functions to analyze, example if empty
+		Functions []string `yaml:"functions"`
+	}
+
+	// DocCoverage configures the doc comment coverage report
+	DocCoverage struct {
+		// Min is the minimum percentage of documented exported declarations of every package, 0 for none
+		Min float64 `yaml:"min"`
+	}
+
+	// Clones configures the clone detection
+	Clones struct {
+		// MinTokens is the minimum number of tokens of a clone, the default of the clone detection if 0
+		MinTokens int `yaml:"minTokens"`
+	}
+)
+
+// FileName is the name of the configuration file
+const FileName = ".analysis.yaml"
+
+// Default returns the configuration used when no configuration file is found
+func Default() *Config {
+	return &Config{}
+}
+
+// Find reads the configuration file of dir, the first .analysis.yaml found in dir or its parents,
+// the default configuration if there is none
+func Find(dir string) (*Config, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		path := filepath.Join(dir, FileName)
+		info, err := os.Stat(path)
+		if err == nil && !info.IsDir() {
+			return Load(path)
+		}
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return Default(), nil
+		}
+		dir = parent
+	}
+}
+
+// Load reads the configuration file, unknown keys are errors so typos do not go unnoticed
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("read %s failed: %w", path, err)
+	}
+
+	config.Path, err = filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// Parse parses the configuration, the globs of a parsed configuration are relative to the working directory
+func Parse(r io.Reader) (*Config, error) {
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+
+	config := Default()
+	if err := decoder.Decode(config); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	if err := config.normalize(); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// Dir returns the directory the globs are relative to
+func (c *Config) Dir() string {
+	if c.Path == "" {
+		return "."
+	}
+
+	return filepath.Dir(c.Path)
+}
+
+// Includes reports whether the file is analyzed, it matches an include glob, if any, and no exclude glob
+func (c *Config) Includes(file string) bool {
+	rel := c.rel(file)
+	if len(c.Include) > 0 && !matchAny(c.Include, rel) {
+		return false
+	}
+
+	return !matchAny(c.Exclude, rel)
+}
+
+// RulesFor returns the rules of the file, the rules of the matching overrides merged into the base rules
+func (c *Config) RulesFor(file string) Rules {
+	rules := c.Rules
+	rel := c.rel(file)
+	for _, override := range c.Overrides {
+		if MatchGlob(override.Path, rel) {
+			rules = rules.Merge(override.Rules)
+		}
+	}
+
+	return rules
+}
+
+// CheckerEnabled reports whether the @check annotation checker runs
+func (c *Config) CheckerEnabled(name string) bool {
+	for _, disabled := range c.Checker.Disable {
+		if disabled == name {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Merge returns the rules with other merged in:
+// a non-empty enable list replaces the enabled rules, disabled rules add up,
+// severities and parameters of other take precedence key by key
+func (r Rules) Merge(other Rules) Rules {
+	merged := Rules{
+		Enable:   r.Enable,
+		Disable:  append(append([]string(nil), r.Disable...), other.Disable...),
+		Severity: make(map[string]string, len(r.Severity)+len(other.Severity)),
+		Params:   make(map[string]map[string]any, len(r.Params)+len(other.Params)),
+	}
+	if len(other.Enable) > 0 {
+		merged.Enable = other.Enable
+	}
+
+	for _, severities := range []map[string]string{r.Severity, other.Severity} {
+		for name, severity := range severities {
+			merged.Severity[name] = severity
+		}
+	}
+
+	for _, params := range []map[string]map[string]any{r.Params, other.Params} {
+		for name, ruleParams := range params {
+			if merged.Params[name] == nil {
+				merged.Params[name] = make(map[string]any, len(ruleParams))
+			}
+			for key, value := range ruleParams {
+				merged.Params[name][key] = value
+			}
+		}
+	}
+
+	return merged
+}
+
+// normalize flattens the rule parameters and validates the globs
+func (c *Config) normalize() error {
+	rules := []*Rules{&c.Rules}
+	for i := range c.Overrides {
+		if c.Overrides[i].Path == "" {
+			return fmt.Errorf("override %d has no path", i+1)
+		}
+		rules = append(rules, &c.Overrides[i].Rules)
+	}
+
+	for _, r := range rules {
+		for name, params := range r.Params {
+			flat := make(map[string]any, len(params))
+			flatten("", params, flat)
+			r.Params[name] = flat
+		}
+	}
+
+	if c.DocCoverage.Min < 0 || c.DocCoverage.Min > 100 {
+		return fmt.Errorf("docCoverage min %v is not a percentage", c.DocCoverage.Min)
+	}
+
+	if c.Clones.MinTokens < 0 {
+		return fmt.Errorf("clones minTokens %d is negative", c.Clones.MinTokens)
+	}
+
+	globs := append(append([]string(nil), c.Include...), c.Exclude...)
+	for _, override := range c.Overrides {
+		globs = append(globs, override.Path)
+	}
+	for _, glob := range globs {
+		if err := CheckGlob(glob); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// rel returns the slash separated path of the file relative to the configuration directory
+func (c *Config) rel(file string) string {
+	dir, err := filepath.Abs(c.Dir())
+	if err != nil {
+		return filepath.ToSlash(file)
+	}
+
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return filepath.ToSlash(file)
+	}
+
+	rel, err := filepath.Rel(dir, abs)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(abs)
+	}
+
+	return filepath.ToSlash(rel)
+}
+
+// flatten flattens the nested maps into dotted keys
+func flatten(prefix string, values map[string]any, flat map[string]any) {
+	for key, value := range values {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		if nested, ok := value.(map[string]any); ok {
+			flatten(key, nested, flat)
+			continue
+		}
+		flat[key] = value
+	}
+}
+
+func matchAny(globs []string, path string) bool {
+	for _, glob := range globs {
+		if MatchGlob(glob, path) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MatchGlob reports whether the slash separated path or one of its parent directories matches the glob,
+// * matches within a path element and ** matches any number of path elements
+func MatchGlob(glob, path string) bool {
+	re, err := globRegexp(glob)
+	if err != nil {
+		return false
+	}
+
+	for {
+		if re.MatchString(path) {
+			return true
+		}
+
+		i := strings.LastIndex(path, "/")
+		if i < 0 {
+			return false
+		}
+		path = path[:i]
+	}
+}
+
+// CheckGlob returns an error if the glob is invalid
+func CheckGlob(glob string) error {
+	if _, err := globRegexp(glob); err != nil {
+		return fmt.Errorf("invalid glob %s: %w", glob, err)
+	}
+
+	return nil
+}
+
+// globRegexp converts the glob into a regular expression
+func globRegexp(glob string) (*regexp.Regexp, error) {
+	glob = strings.TrimPrefix(filepath.ToSlash(glob), "./")
+
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				i++
+				if i+1 < len(glob) && glob[i+1] == '/' {
+					// **/ matches zero or more directories
+					i++
+					b.WriteString("(?:.*/)?")
+				} else {
+					b.WriteString(".*")
+				}
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+
+	return regexp.Compile(b.String())
+}
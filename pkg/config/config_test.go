@@ -0,0 +1,171 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	testAssert "github.com/stretchr/testify/assert"
+)
+
+const testConfig = `
+format: sarif
+include:
+  - pkg/**
+  - cmd
+exclude:
+  - "**/*_test.go"
+  - pkg/generated
+rules:
+  disable: [ident-length]
+  severity:
+    nesting-depth: error
+  params:
+    nesting-depth:
+      max-depth: 3
+    naming:
+      min-length:
+        var: 2
+        func: 3
+overrides:
+  - path: pkg/legacy
+    rules:
+      disable: [naming]
+      params:
+        nesting-depth:
+          max-depth: 6
+checker:
+  disable: [Range]
+parity:
+  functions: [example, other]
+docCoverage:
+  min: 80
+clones:
+  minTokens: 40
+`
+
+func writeConfig(t *testing.T, dir, content string) string {
+	path := filepath.Join(dir, FileName)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s failed: %v", path, err)
+	}
+	return path
+}
+
+func TestParse(t *testing.T) {
+	assert := testAssert.New(t)
+	config, err := Parse(strings.NewReader(testConfig))
+	assert.NoError(err)
+
+	assert.Equal("sarif", config.Format)
+	assert.Equal([]string{"ident-length"}, config.Rules.Disable)
+	assert.Equal(map[string]string{"nesting-depth": "error"}, config.Rules.Severity)
+	assert.Equal(map[string]map[string]any{
+		"nesting-depth": {"max-depth": 3},
+		"naming":        {"min-length.var": 2, "min-length.func": 3},
+	}, config.Rules.Params)
+	assert.Equal([]string{"example", "other"}, config.Parity.Functions)
+	assert.Equal(80.0, config.DocCoverage.Min)
+	assert.Equal(40, config.Clones.MinTokens)
+	assert.False(config.CheckerEnabled("Range"))
+	assert.True(config.CheckerEnabled("NotNullable"))
+
+	_, err = Parse(strings.NewReader("rule:\n  enable: [naming]\n"))
+	assert.Error(err)
+	_, err = Parse(strings.NewReader("overrides:\n  - rules:\n      disable: [naming]\n"))
+	assert.Error(err)
+	_, err = Parse(strings.NewReader("docCoverage:\n  min: 120\n"))
+	assert.Error(err)
+	_, err = Parse(strings.NewReader("clones:\n  minTokens: -1\n"))
+	assert.Error(err)
+
+	config, err = Parse(strings.NewReader(""))
+	assert.NoError(err)
+	assert.Equal(Default(), config)
+}
+
+func TestFind(t *testing.T) {
+	assert := testAssert.New(t)
+	root := t.TempDir()
+	path := writeConfig(t, root, testConfig)
+	dir := filepath.Join(root, "pkg", "service")
+	assert.NoError(os.MkdirAll(dir, 0o755))
+
+	config, err := Find(dir)
+	assert.NoError(err)
+	assert.Equal(path, config.Path)
+	assert.Equal(root, config.Dir())
+
+	nested := writeConfig(t, dir, "format: json\n")
+	config, err = Find(dir)
+	assert.NoError(err)
+	assert.Equal(nested, config.Path)
+	assert.Equal("json", config.Format)
+
+	writeConfig(t, dir, "format: [json]\n")
+	_, err = Find(dir)
+	assert.Error(err)
+}
+
+func TestConfig_Includes(t *testing.T) {
+	assert := testAssert.New(t)
+	root := t.TempDir()
+	config, err := Load(writeConfig(t, root, testConfig))
+	assert.NoError(err)
+
+	assert.True(config.Includes(filepath.Join(root, "pkg", "ast", "engine.go")))
+	assert.True(config.Includes(filepath.Join(root, "cmd", "lint", "main.go")))
+	assert.False(config.Includes(filepath.Join(root, "pkg", "ast", "engine_test.go")))
+	assert.False(config.Includes(filepath.Join(root, "pkg", "generated", "types.go")))
+	assert.False(config.Includes(filepath.Join(root, "tests", "example.go")))
+	assert.True(Default().Includes("example.go"))
+}
+
+func TestConfig_RulesFor(t *testing.T) {
+	assert := testAssert.New(t)
+	root := t.TempDir()
+	config, err := Load(writeConfig(t, root, testConfig))
+	assert.NoError(err)
+
+	rules := config.RulesFor(filepath.Join(root, "pkg", "service", "job.go"))
+	assert.Equal(config.Rules.Disable, rules.Disable)
+	assert.Equal(3, rules.Params["nesting-depth"]["max-depth"])
+
+	rules = config.RulesFor(filepath.Join(root, "pkg", "legacy", "old", "job.go"))
+	assert.Equal([]string{"ident-length", "naming"}, rules.Disable)
+	assert.Equal("error", rules.Severity["nesting-depth"])
+	assert.Equal(6, rules.Params["nesting-depth"]["max-depth"])
+	assert.Equal(2, rules.Params["naming"]["min-length.var"])
+
+	// merging does not change the base rules
+	assert.Equal(3, config.Rules.Params["nesting-depth"]["max-depth"])
+}
+
+func TestMatchGlob(t *testing.T) {
+	assert := testAssert.New(t)
+	assert.True(MatchGlob("pkg/*.go", "pkg/a.go"))
+	assert.False(MatchGlob("pkg/*.go", "pkg/sub/a.go"))
+	assert.True(MatchGlob("pkg/**/*.go", "pkg/a.go"))
+	assert.True(MatchGlob("pkg/**/*.go", "pkg/sub/deep/a.go"))
+	assert.True(MatchGlob("**/testdata", "pkg/ast/testdata/src/a.go"))
+	assert.True(MatchGlob("./pkg/a?.go", "pkg/ab.go"))
+	assert.False(MatchGlob("pkg", "pkgs/a.go"))
+}
@@ -0,0 +1,255 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lint
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/LokiWager/analysis-demo/pkg/ast"
+)
+
+type (
+	// Baseline records known findings, so only new findings are reported
+	// findings are keyed by rule, file and a fingerprint of their source line,
+	// so they survive line shifts, files are relative to the root of the baseline,
+	// so the baseline applies whatever the working directory
+	Baseline struct {
+		// counts of the known findings by key, a key may match several findings
+		counts map[baselineKey]int
+
+		// root is the absolute directory the files of the keys are relative to
+		root string
+	}
+
+	baselineKey struct {
+		Rule        string `json:"rule"`
+		File        string `json:"file"`
+		Fingerprint string `json:"fingerprint"`
+	}
+
+	baselineEntry struct {
+		baselineKey
+		Count int `json:"count"`
+	}
+
+	baselineFile struct {
+		Version  int             `json:"version"`
+		Findings []baselineEntry `json:"findings"`
+	}
+
+	// sourceLines reads and caches the lines of the source files
+	sourceLines map[string][]string
+)
+
+// baselineVersion is the version of the baseline file format
+const baselineVersion = 1
+
+// NewBaseline creates a baseline from the findings, their files are recorded relative to root,
+// the directory of the project configuration or the module root
+func NewBaseline(findings []ast.Finding, root string) (*Baseline, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &Baseline{counts: make(map[baselineKey]int), root: root}
+	lines := sourceLines{}
+	for _, finding := range findings {
+		key, err := b.key(finding, lines)
+		if err != nil {
+			return nil, err
+		}
+		b.counts[key]++
+	}
+
+	return b, nil
+}
+
+// ReadBaseline reads a baseline from the file, its files are relative to root like in NewBaseline
+func ReadBaseline(path, root string) (*Baseline, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc baselineFile
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse baseline %s failed: %w", path, err)
+	}
+	if doc.Version != baselineVersion {
+		return nil, fmt.Errorf("baseline %s has version %d, want %d", path, doc.Version, baselineVersion)
+	}
+
+	b := &Baseline{counts: make(map[baselineKey]int, len(doc.Findings)), root: root}
+	for _, entry := range doc.Findings {
+		b.counts[entry.baselineKey] += entry.Count
+	}
+
+	return b, nil
+}
+
+// Write writes the baseline to the file, entries are sorted so the file is stable
+func (b *Baseline) Write(path string) error {
+	doc := baselineFile{
+		Version:  baselineVersion,
+		Findings: make([]baselineEntry, 0, len(b.counts)),
+	}
+	for key, count := range b.counts {
+		doc.Findings = append(doc.Findings, baselineEntry{baselineKey: key, Count: count})
+	}
+	sort.Slice(doc.Findings, func(i, j int) bool {
+		a, b := doc.Findings[i], doc.Findings[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Rule != b.Rule {
+			return a.Rule < b.Rule
+		}
+		return a.Fingerprint < b.Fingerprint
+	})
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Len returns the number of findings in the baseline
+func (b *Baseline) Len() int {
+	n := 0
+	for _, count := range b.counts {
+		n += count
+	}
+
+	return n
+}
+
+// Filter returns the findings that are not in the baseline,
+// when a key matches more findings than recorded, the extra ones are new
+func (b *Baseline) Filter(findings []ast.Finding) ([]ast.Finding, error) {
+	remaining := make(map[baselineKey]int, len(b.counts))
+	for key, count := range b.counts {
+		remaining[key] = count
+	}
+
+	lines := sourceLines{}
+	fresh := make([]ast.Finding, 0)
+	for _, finding := range findings {
+		key, err := b.key(finding, lines)
+		if err != nil {
+			return nil, err
+		}
+		if remaining[key] > 0 {
+			remaining[key]--
+			continue
+		}
+		fresh = append(fresh, finding)
+	}
+
+	return fresh, nil
+}
+
+// key fingerprints the finding with its rule, subject and the trimmed source line,
+// the line number is left out so the key survives line shifts.
+// A finding without a file, like a load error, has no source line, its message is fingerprinted instead
+func (b *Baseline) key(finding ast.Finding, lines sourceLines) (baselineKey, error) {
+	parts := []string{finding.Rule, finding.Subject, finding.Message}
+	file := ""
+	if finding.Position.Filename != "" {
+		line, err := lines.line(finding.Position.Filename, finding.Position.Line)
+		if err != nil {
+			return baselineKey{}, err
+		}
+		parts = []string{finding.Rule, finding.Subject, strings.TrimSpace(line)}
+
+		if file, err = b.rel(finding.Position.Filename); err != nil {
+			return baselineKey{}, err
+		}
+	}
+
+	hash := sha256.New()
+	for _, part := range parts {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+
+	return baselineKey{
+		Rule:        finding.Rule,
+		File:        file,
+		Fingerprint: hex.EncodeToString(hash.Sum(nil))[:16],
+	}, nil
+}
+
+// rel returns the slash separated path of the file relative to the root of the baseline,
+// the absolute path of files outside of it
+func (b *Baseline) rel(file string) (string, error) {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return "", err
+	}
+
+	rel, err := filepath.Rel(b.root, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return filepath.ToSlash(abs), nil
+	}
+
+	return filepath.ToSlash(rel), nil
+}
+
+// line returns the line of the file, lines start at 1, an empty string if out of range
+func (s sourceLines) line(file string, line int) (string, error) {
+	lines, exists := s[file]
+	if !exists {
+		f, err := os.Open(file)
+		if err != nil {
+			return "", err
+		}
+		defer f.Close()
+
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(nil, 1024*1024)
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+		if err := scanner.Err(); err != nil {
+			return "", err
+		}
+		s[file] = lines
+	}
+
+	if line < 1 || line > len(lines) {
+		return "", nil
+	}
+
+	return lines[line-1], nil
+}
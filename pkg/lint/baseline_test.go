@@ -0,0 +1,137 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	testAssert "github.com/stretchr/testify/assert"
+
+	"github.com/LokiWager/analysis-demo/pkg/ast"
+)
+
+func lintSource(t *testing.T, path, src string) []ast.Finding {
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatalf("write %s failed: %v", path, err)
+	}
+	e, err := ast.NewEngine(path, nil)
+	if err != nil {
+		t.Fatalf("new engine failed: %v", err)
+	}
+	return e.Run(ast.NewIdentLengthRule())
+}
+
+func TestBaseline(t *testing.T) {
+	assert := testAssert.New(t)
+	dir := t.TempDir()
+	file := filepath.Join(dir, "example.go")
+	baselinePath := filepath.Join(dir, "baseline.json")
+
+	findings := lintSource(t, file, `package main
+
+func idEqual13xxxx() {
+	anotherIdEqua := 1
+	_ = anotherIdEqua
+}
+`)
+	assert.Len(findings, 3)
+
+	baseline, err := NewBaseline(findings, dir)
+	assert.NoError(err)
+	assert.NoError(baseline.Write(baselinePath))
+
+	baseline, err = ReadBaseline(baselinePath, dir)
+	assert.NoError(err)
+	assert.Equal(3, baseline.Len())
+
+	t.Run("Files relative to the root", func(t *testing.T) {
+		data, err := os.ReadFile(baselinePath)
+		assert.NoError(err)
+		assert.Contains(string(data), `"file": "example.go"`)
+		assert.NotContains(string(data), dir)
+
+		// the same file named relative to the working directory is known
+		wd, err := os.Getwd()
+		assert.NoError(err)
+		rel, err := filepath.Rel(wd, file)
+		assert.NoError(err)
+		relative := append([]ast.Finding(nil), findings...)
+		for i := range relative {
+			relative[i].Position.Filename = rel
+		}
+		fresh, err := baseline.Filter(relative)
+		assert.NoError(err)
+		assert.Empty(fresh)
+	})
+
+	t.Run("Findings without a file", func(t *testing.T) {
+		load := ast.Finding{Rule: ast.SyntaxErrorRuleName, Message: "package a: no Go files"}
+		withLoad, err := NewBaseline([]ast.Finding{load}, dir)
+		assert.NoError(err)
+		fresh, err := withLoad.Filter([]ast.Finding{load, {Rule: load.Rule, Message: "package b: no Go files"}})
+		assert.NoError(err)
+		if assert.Len(fresh, 1) {
+			assert.Equal("package b: no Go files", fresh[0].Message)
+		}
+	})
+
+	t.Run("Known findings after a line shift", func(t *testing.T) {
+		findings := lintSource(t, file, `package main
+
+import "fmt"
+
+func idEqual13xxxx() {
+	anotherIdEqua := 1
+	_ = anotherIdEqua
+	fmt.Println()
+}
+`)
+		fresh, err := baseline.Filter(findings)
+		assert.NoError(err)
+		assert.Empty(fresh)
+	})
+
+	t.Run("New findings", func(t *testing.T) {
+		findings := lintSource(t, file, `package main
+
+func idEqual13xxxx() {
+	anotherIdEqua := 1
+	_ = anotherIdEqua
+	_ = anotherIdEqua
+	thirdIdEqual1 := 2
+	_ = thirdIdEqual1
+}
+`)
+		fresh, err := baseline.Filter(findings)
+		assert.NoError(err)
+		// the repeated line exceeds the recorded count of its key
+		assert.Len(fresh, 3)
+		assert.Equal("anotherIdEqua", fresh[0].Subject)
+		assert.Equal(6, fresh[0].Position.Line)
+		assert.Equal("thirdIdEqual1", fresh[1].Subject)
+		assert.Equal("thirdIdEqual1", fresh[2].Subject)
+	})
+
+	t.Run("Invalid baseline", func(t *testing.T) {
+		assert.NoError(os.WriteFile(baselinePath, []byte(`{"version": 2}`), 0o644))
+		_, err := ReadBaseline(baselinePath, dir)
+		assert.Error(err)
+	})
+}
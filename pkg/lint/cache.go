@@ -0,0 +1,125 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lint
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/LokiWager/analysis-demo/pkg/ast"
+)
+
+type (
+	// Cache stores the findings of the files on disk, keyed by the content of the file,
+	// the rule-set version and the configuration, so unchanged files are not analyzed again
+	Cache struct {
+		// dir is the directory of the cache entries
+		dir string
+	}
+)
+
+// cacheDirName is the name of the default cache directory in the user cache directory
+const cacheDirName = "analysis-lint"
+
+// DefaultCacheDir returns the default cache directory in the user cache directory
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, cacheDirName), nil
+}
+
+// NewCache creates the cache in the directory, the directory is created if not exists
+func NewCache(dir string) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create cache directory %s failed: %w", dir, err)
+	}
+
+	return &Cache{dir: dir}, nil
+}
+
+// Key returns the cache key of the file content analyzed with the configuration
+func (c *Cache) Key(file string, content []byte, configHash string) string {
+	hash := sha256.New()
+	for _, part := range []string{strconv.Itoa(ast.RulesVersion), configHash, file} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	hash.Write(content)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Get returns the cached findings of the key, false if not cached
+func (c *Cache) Get(key string) ([]ast.Finding, bool) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+
+	var findings []ast.Finding
+	if err := json.Unmarshal(data, &findings); err != nil {
+		return nil, false
+	}
+
+	return findings, true
+}
+
+// Put caches the findings of the key
+func (c *Cache) Put(key string, findings []ast.Finding) error {
+	data, err := json.Marshal(findings)
+	if err != nil {
+		return err
+	}
+
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	// write to a temporary file first, so concurrent runs never read a partial entry
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+
+	_, err = tmp.Write(data)
+	err = errors.Join(err, tmp.Close())
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		// err is returned, a temporary file left behind is only wasted space
+		_ = os.Remove(tmp.Name())
+	}
+
+	return err
+}
+
+// path returns the path of the entry of the key, entries are spread in sub directories by key prefix
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key[:2], key)
+}
@@ -0,0 +1,257 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/LokiWager/analysis-demo/pkg/ast"
+)
+
+type (
+	// CloneType is the type of a clone
+	CloneType string
+
+	// ClonesConfig is the configuration of the clone detection
+	ClonesConfig struct {
+		// MinTokens is the minimum number of tokens of a clone
+		MinTokens int
+	}
+
+	// ClonePair is a pair of fragments of code with the same syntax tree, the largest clones are reported,
+	// not the clones overlapping them
+	ClonePair struct {
+		// Type is type-1 for identical code and type-2 for code differing by identifiers and literals
+		Type CloneType
+
+		// Similarity is the share of the identifiers and literals of the fragments that are equal, 1 for type-1
+		Similarity float64
+
+		// First is the first fragment of the pair in file order
+		First ast.CloneFragment
+
+		// Second is the second fragment of the pair
+		Second ast.CloneFragment
+	}
+
+	jsonClonesReport struct {
+		MinTokens int             `json:"minTokens"`
+		Clones    []jsonClonePair `json:"clones"`
+	}
+
+	jsonClonePair struct {
+		Type       CloneType         `json:"type"`
+		Tokens     int               `json:"tokens"`
+		Similarity float64           `json:"similarity"`
+		Locations  []jsonCloneRegion `json:"locations"`
+	}
+
+	jsonCloneRegion struct {
+		File      string `json:"file"`
+		Line      int    `json:"line"`
+		Column    int    `json:"column"`
+		EndLine   int    `json:"endLine"`
+		EndColumn int    `json:"endColumn"`
+	}
+)
+
+const (
+	// CloneType1 is the type of the clones with identical code, positions and comments excepted
+	CloneType1 CloneType = "type-1"
+
+	// CloneType2 is the type of the clones whose code differs by identifiers and literals
+	CloneType2 CloneType = "type-2"
+
+	// DefaultMinCloneTokens is the minimum number of tokens of a clone when none is configured
+	DefaultMinCloneTokens = 50
+)
+
+// Clones detects the clones of at least MinTokens tokens among the files,
+// pairs are ordered from the largest clone, then by position
+func Clones(files []string, config *ClonesConfig) ([]ClonePair, error) {
+	minTokens := config.MinTokens
+	if minTokens <= 0 {
+		minTokens = DefaultMinCloneTokens
+	}
+
+	fileSet := token.NewFileSet()
+	byHash := make(map[uint64][]ast.CloneFragment)
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		// the partial syntax tree of a file with syntax errors is still compared
+		parsed, err := parser.ParseFile(fileSet, file, src, parser.AllErrors)
+		if parsed == nil {
+			return nil, fmt.Errorf("parse file %s failed: %w", file, err)
+		}
+
+		for _, fragment := range ast.CloneFragments(fileSet, parsed, src, minTokens) {
+			byHash[fragment.Normalized] = append(byHash[fragment.Normalized], fragment)
+		}
+	}
+
+	// a fragment is paired with the next fragment of its class not overlapping it, so a class of n fragments
+	// gives n-1 pairs and not n², repetitive code such as tables would give millions
+	var candidates []ClonePair
+	for _, fragments := range byHash {
+		sort.Slice(fragments, func(i, j int) bool {
+			return positionBefore(fragments[i].Position, fragments[j].Position)
+		})
+		for i := range fragments {
+			for j := i + 1; j < len(fragments); j++ {
+				if !fragments[i].Overlaps(fragments[j]) {
+					candidates = append(candidates, newClonePair(fragments[i], fragments[j]))
+					break
+				}
+			}
+		}
+	}
+	sortClonePairs(candidates)
+
+	// the clones whose fragments overlap the fragments of a larger clone are not reported,
+	// they are part of it or repeat the same code
+	var pairs []ClonePair
+	var reported []ast.CloneFragment
+	for _, candidate := range candidates {
+		if overlapsAny(reported, candidate.First) && overlapsAny(reported, candidate.Second) {
+			continue
+		}
+		pairs = append(pairs, candidate)
+		reported = append(reported, candidate.First, candidate.Second)
+	}
+
+	return pairs, nil
+}
+
+// newClonePair returns the pair of the fragments in file order
+func newClonePair(a, b ast.CloneFragment) ClonePair {
+	if positionBefore(b.Position, a.Position) {
+		a, b = b, a
+	}
+
+	if a.Exact == b.Exact {
+		return ClonePair{Type: CloneType1, Similarity: 1, First: a, Second: b}
+	}
+
+	return ClonePair{Type: CloneType2, Similarity: a.Similarity(b), First: a, Second: b}
+}
+
+// positionBefore reports whether the position is before the other one in file order
+func positionBefore(a, b token.Position) bool {
+	if a.Filename != b.Filename {
+		return a.Filename < b.Filename
+	}
+
+	return a.Offset < b.Offset
+}
+
+// overlapsAny reports whether the fragment overlaps one of the fragments
+func overlapsAny(fragments []ast.CloneFragment, fragment ast.CloneFragment) bool {
+	for _, other := range fragments {
+		if other.Overlaps(fragment) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sortClonePairs orders the pairs from the largest clone, ties are broken by the positions
+func sortClonePairs(pairs []ClonePair) {
+	sort.Slice(pairs, func(i, j int) bool {
+		a, b := pairs[i], pairs[j]
+		if a.First.Tokens != b.First.Tokens {
+			return a.First.Tokens > b.First.Tokens
+		}
+		for _, positions := range [][2]token.Position{{a.First.Position, b.First.Position}, {a.Second.Position, b.Second.Position}} {
+			if positions[0].Filename != positions[1].Filename {
+				return positions[0].Filename < positions[1].Filename
+			}
+			if positions[0].Offset != positions[1].Offset {
+				return positions[0].Offset < positions[1].Offset
+			}
+		}
+		// a sequence of statements ends after the last statement of the shorter one
+		return a.First.End.Offset > b.First.End.Offset
+	})
+}
+
+// WriteClones writes the clone pairs to w as text or JSON
+func WriteClones(w io.Writer, format Format, pairs []ClonePair, minTokens int) error {
+	switch format {
+	case FormatText:
+		return writeClonesText(w, pairs)
+	case FormatJSON:
+		return writeClonesJSON(w, pairs, minTokens)
+	}
+
+	return fmt.Errorf("format %s not supported by the clones report, use %s or %s", format, FormatText, FormatJSON)
+}
+
+func writeClonesText(w io.Writer, pairs []ClonePair) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "TYPE\tTOKENS\tSIMILARITY\tFIRST\tSECOND")
+	for _, pair := range pairs {
+		fmt.Fprintf(tw, "%s\t%d\t%.0f%%\t%s\t%s\n", pair.Type, pair.First.Tokens, 100*pair.Similarity,
+			cloneRegion(pair.First), cloneRegion(pair.Second))
+	}
+
+	return tw.Flush()
+}
+
+// cloneRegion returns the file and the lines of the fragment
+func cloneRegion(fragment ast.CloneFragment) string {
+	return fmt.Sprintf("%s:%d-%d", fragment.Position.Filename, fragment.Position.Line, fragment.End.Line)
+}
+
+func writeClonesJSON(w io.Writer, pairs []ClonePair, minTokens int) error {
+	doc := jsonClonesReport{MinTokens: minTokens, Clones: make([]jsonClonePair, 0, len(pairs))}
+	for _, pair := range pairs {
+		locations := make([]jsonCloneRegion, 0, 2)
+		for _, fragment := range []ast.CloneFragment{pair.First, pair.Second} {
+			locations = append(locations, jsonCloneRegion{
+				File:      fragment.Position.Filename,
+				Line:      fragment.Position.Line,
+				Column:    fragment.Position.Column,
+				EndLine:   fragment.End.Line,
+				EndColumn: fragment.End.Column,
+			})
+		}
+
+		doc.Clones = append(doc.Clones, jsonClonePair{
+			Type:       pair.Type,
+			Tokens:     pair.First.Tokens,
+			Similarity: pair.Similarity,
+			Locations:  locations,
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
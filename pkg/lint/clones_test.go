@@ -0,0 +1,170 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	testAssert "github.com/stretchr/testify/assert"
+)
+
+const cloneSource = `package trace
+
+import "os/exec"
+
+func start(name string, args []string) (*exec.Cmd, error) {
+	cmd := exec.Command(name, args...)
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	for i := 0; i < len(args); i++ {
+		if args[i] == "" {
+			return nil, nil
+		}
+	}
+	return cmd, nil
+}
+`
+
+func writeCloneFiles(t *testing.T) []string {
+	dir := t.TempDir()
+	sources := map[string]string{
+		"trace.go": cloneSource,
+		// a type-1 clone with another function name and a type-2 clone of the loop
+		"trace_win.go": `package trace
+
+import "os/exec"
+
+func run(name string, args []string) (*exec.Cmd, error) {
+	cmd := exec.Command(name, args...)
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	for i := 0; i < len(args); i++ {
+		if args[i] == "" {
+			return nil, nil
+		}
+	}
+	return cmd, nil
+}
+
+func find(values []string) ([]string, error) {
+	for j := 0; j < len(values); j++ {
+		if values[j] == "-" {
+			return nil, nil
+		}
+	}
+	return values, nil
+}
+`,
+	}
+
+	var files []string
+	for _, name := range []string{"trace.go", "trace_win.go"} {
+		file := filepath.Join(dir, name)
+		if err := os.WriteFile(file, []byte(sources[name]), 0o644); err != nil {
+			t.Fatalf("write %s failed: %v", file, err)
+		}
+		files = append(files, file)
+	}
+
+	return files
+}
+
+func TestClones(t *testing.T) {
+	assert := testAssert.New(t)
+	files := writeCloneFiles(t)
+
+	pairs, err := Clones(files, &ClonesConfig{MinTokens: 20})
+	assert.NoError(err)
+	if assert.Len(pairs, 2) {
+		// the functions differ by their names only, a type-2 clone containing the type-1 clones of their bodies
+		assert.Equal(CloneType2, pairs[0].Type)
+		assert.Equal(files[0], pairs[0].First.Position.Filename)
+		assert.Equal(5, pairs[0].First.Position.Line)
+		assert.Equal(16, pairs[0].First.End.Line)
+		assert.Equal(files[1], pairs[0].Second.Position.Filename)
+		assert.InDelta(0.97, pairs[0].Similarity, 0.01)
+
+		// the loop of find is a type-2 clone of the loops of start and run, reported once
+		assert.Equal(CloneType2, pairs[1].Type)
+		assert.Equal(10, pairs[1].First.Position.Line)
+		assert.Equal(files[1], pairs[1].Second.Position.Filename)
+		assert.Equal(19, pairs[1].Second.Position.Line)
+		assert.Less(pairs[1].Similarity, 1.0)
+	}
+
+	// identical files are a type-1 clone
+	assert.NoError(os.WriteFile(files[1], []byte(cloneSource), 0o644))
+	pairs, err = Clones(files, &ClonesConfig{MinTokens: 20})
+	assert.NoError(err)
+	if assert.Len(pairs, 1) {
+		assert.Equal(CloneType1, pairs[0].Type)
+		assert.Equal(1.0, pairs[0].Similarity)
+	}
+
+	pairs, err = Clones(files, &ClonesConfig{MinTokens: 100})
+	assert.NoError(err)
+	assert.Empty(pairs)
+}
+
+func TestClones_Repetitive(t *testing.T) {
+	assert := testAssert.New(t)
+	var src strings.Builder
+	src.WriteString("package table\n\nfunc fill(values map[string]int) {\n")
+	for i := 0; i < 2000; i++ {
+		fmt.Fprintf(&src, "\tvalues[\"key%d\"] = values[\"key%d\"] + %d\n", i, i, i)
+	}
+	src.WriteString("}\n")
+	file := filepath.Join(t.TempDir(), "table.go")
+	assert.NoError(os.WriteFile(file, []byte(src.String()), 0o644))
+
+	// a long block of similar statements yields a chain of clones, not every pair of its windows
+	pairs, err := Clones([]string{file}, &ClonesConfig{MinTokens: 20})
+	assert.NoError(err)
+	assert.NotEmpty(pairs)
+	assert.Less(len(pairs), 2000)
+}
+
+func TestWriteClones(t *testing.T) {
+	assert := testAssert.New(t)
+	pairs, err := Clones(writeCloneFiles(t), &ClonesConfig{MinTokens: 20})
+	assert.NoError(err)
+
+	var buf bytes.Buffer
+	assert.NoError(WriteClones(&buf, FormatText, pairs, 20))
+	assert.Regexp(`type-2 +\d+ +97% +.*trace\.go:5-16 +.*trace_win\.go:5-16`, buf.String())
+
+	buf.Reset()
+	assert.NoError(WriteClones(&buf, FormatJSON, pairs, 20))
+	var doc jsonClonesReport
+	assert.NoError(json.Unmarshal(buf.Bytes(), &doc))
+	assert.Equal(20, doc.MinTokens)
+	if assert.Len(doc.Clones, 2) && assert.Len(doc.Clones[0].Locations, 2) {
+		assert.Equal(5, doc.Clones[0].Locations[1].Line)
+		assert.Equal(16, doc.Clones[0].Locations[1].EndLine)
+	}
+
+	assert.Error(WriteClones(&buf, FormatSARIF, pairs, 20))
+}
@@ -0,0 +1,235 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/LokiWager/analysis-demo/pkg/ast"
+)
+
+type (
+	// Metric is a complexity metric the complexity report is ordered by
+	Metric string
+
+	// ComplexityConfig is the configuration of a complexity report
+	ComplexityConfig struct {
+		// Top is the number of functions listed per package, all functions if not positive
+		Top int
+
+		// SortBy is the metric the functions are ordered by, the cyclomatic complexity if empty
+		SortBy Metric
+	}
+
+	// PackageComplexity lists the most complex functions of a package
+	PackageComplexity struct {
+		// Dir is the directory of the package
+		Dir string
+
+		// Name is the name of the package
+		Name string
+
+		// Count is the number of functions of the package
+		Count int
+
+		// Functions are the most complex functions, from the most complex one
+		Functions []ast.FunctionComplexity
+	}
+
+	jsonComplexityReport struct {
+		Packages []jsonPackageComplexity `json:"packages"`
+	}
+
+	jsonPackageComplexity struct {
+		Dir       string                   `json:"dir"`
+		Name      string                   `json:"name"`
+		Count     int                      `json:"count"`
+		Functions []jsonFunctionComplexity `json:"functions"`
+	}
+
+	jsonFunctionComplexity struct {
+		Name       string `json:"name"`
+		File       string `json:"file"`
+		Line       int    `json:"line"`
+		Column     int    `json:"column"`
+		Cyclomatic int    `json:"cyclomatic"`
+		Cognitive  int    `json:"cognitive"`
+	}
+
+	// packageKey identifies a package of a report, a directory may hold a package and its _test package
+	packageKey struct {
+		dir, name string
+	}
+)
+
+const (
+	// MetricCyclomatic orders functions by McCabe cyclomatic complexity
+	MetricCyclomatic Metric = "cyclomatic"
+
+	// MetricCognitive orders functions by cognitive complexity
+	MetricCognitive Metric = "cognitive"
+)
+
+// ParseMetric parses the name of a complexity metric
+func ParseMetric(name string) (Metric, error) {
+	switch metric := Metric(name); metric {
+	case MetricCyclomatic, MetricCognitive:
+		return metric, nil
+	}
+
+	return "", fmt.Errorf("metric %s not supported, use one of %s, %s", name, MetricCyclomatic, MetricCognitive)
+}
+
+// Complexity computes the complexity of the functions of the files and lists the top offenders per package,
+// packages are ordered by directory and name
+func Complexity(files []string, config *ComplexityConfig) ([]PackageComplexity, error) {
+	byPackage := make(map[packageKey][]ast.FunctionComplexity)
+	for _, file := range files {
+		e, err := ast.NewEngine(file, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, function := range e.Complexities() {
+			key := packageKey{dir: filepath.Dir(file), name: function.Package}
+			byPackage[key] = append(byPackage[key], function)
+		}
+	}
+
+	packages := make([]PackageComplexity, 0, len(byPackage))
+	for key, functions := range byPackage {
+		config.sort(functions)
+
+		top := functions
+		if config.Top > 0 && len(top) > config.Top {
+			top = top[:config.Top]
+		}
+
+		packages = append(packages, PackageComplexity{
+			Dir:       key.dir,
+			Name:      key.name,
+			Count:     len(functions),
+			Functions: top,
+		})
+	}
+
+	sortPackages(packages, func(i int) packageKey {
+		return packageKey{packages[i].Dir, packages[i].Name}
+	})
+
+	return packages, nil
+}
+
+// sortPackages orders the packages of a report by directory then name, key returns the key of the package i
+func sortPackages(packages any, key func(i int) packageKey) {
+	sort.Slice(packages, func(i, j int) bool {
+		a, b := key(i), key(j)
+		if a.dir != b.dir {
+			return a.dir < b.dir
+		}
+		return a.name < b.name
+	})
+}
+
+// sort orders the functions from the most complex one, ties are broken by the other metric and the position
+func (c *ComplexityConfig) sort(functions []ast.FunctionComplexity) {
+	metrics := func(f ast.FunctionComplexity) (int, int) {
+		if c.SortBy == MetricCognitive {
+			return f.Cognitive, f.Cyclomatic
+		}
+		return f.Cyclomatic, f.Cognitive
+	}
+
+	sort.SliceStable(functions, func(i, j int) bool {
+		firstI, secondI := metrics(functions[i])
+		firstJ, secondJ := metrics(functions[j])
+		if firstI != firstJ {
+			return firstI > firstJ
+		}
+		if secondI != secondJ {
+			return secondI > secondJ
+		}
+
+		pi, pj := functions[i].Position, functions[j].Position
+		if pi.Filename != pj.Filename {
+			return pi.Filename < pj.Filename
+		}
+		return pi.Offset < pj.Offset
+	})
+}
+
+// WriteComplexity writes the complexity report to w as text or JSON
+func WriteComplexity(w io.Writer, format Format, packages []PackageComplexity) error {
+	switch format {
+	case FormatText:
+		return writeComplexityText(w, packages)
+	case FormatJSON:
+		return writeComplexityJSON(w, packages)
+	}
+
+	return fmt.Errorf("format %s not supported by the complexity report, use %s or %s", format, FormatText, FormatJSON)
+}
+
+func writeComplexityText(w io.Writer, packages []PackageComplexity) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for i, pkg := range packages {
+		if i > 0 {
+			fmt.Fprintln(tw)
+		}
+		fmt.Fprintf(tw, "%s (package %s, %d functions)\n", pkg.Dir, pkg.Name, pkg.Count)
+		fmt.Fprintln(tw, "  CYCLOMATIC\tCOGNITIVE\tFUNCTION\tPOSITION")
+		for _, function := range pkg.Functions {
+			fmt.Fprintf(tw, "  %d\t%d\t%s\t%s\n", function.Cyclomatic, function.Cognitive, function.Name, function.Position)
+		}
+	}
+
+	return tw.Flush()
+}
+
+func writeComplexityJSON(w io.Writer, packages []PackageComplexity) error {
+	doc := jsonComplexityReport{Packages: make([]jsonPackageComplexity, 0, len(packages))}
+	for _, pkg := range packages {
+		functions := make([]jsonFunctionComplexity, 0, len(pkg.Functions))
+		for _, function := range pkg.Functions {
+			functions = append(functions, jsonFunctionComplexity{
+				Name:       function.Name,
+				File:       function.Position.Filename,
+				Line:       function.Position.Line,
+				Column:     function.Position.Column,
+				Cyclomatic: function.Cyclomatic,
+				Cognitive:  function.Cognitive,
+			})
+		}
+
+		doc.Packages = append(doc.Packages, jsonPackageComplexity{
+			Dir:       pkg.Dir,
+			Name:      pkg.Name,
+			Count:     pkg.Count,
+			Functions: functions,
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
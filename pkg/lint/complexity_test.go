@@ -0,0 +1,103 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	testAssert "github.com/stretchr/testify/assert"
+)
+
+func writeComplexityFiles(t *testing.T) []string {
+	dir := t.TempDir()
+	sources := map[string]string{
+		"a/a.go": "package a\n\nfunc one() {}\n\nfunc two(x bool) {\n\tif x {\n\t}\n}\n\nfunc three(x, y bool) {\n\tif x && y {\n\t}\n}\n",
+		"b/b.go": "package b\n\nfunc flat(x int) {\n\tswitch x {\n\tcase 1:\n\tcase 2:\n\tcase 3:\n\t}\n}\n\nfunc nested(x bool) {\n\tif x {\n\t\tif x {\n\t\t}\n\t}\n}\n",
+	}
+
+	var files []string
+	for _, name := range []string{"a/a.go", "b/b.go"} {
+		file := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			t.Fatalf("create %s failed: %v", file, err)
+		}
+		if err := os.WriteFile(file, []byte(sources[name]), 0o644); err != nil {
+			t.Fatalf("write %s failed: %v", file, err)
+		}
+		files = append(files, file)
+	}
+
+	return files
+}
+
+func TestComplexity(t *testing.T) {
+	assert := testAssert.New(t)
+	files := writeComplexityFiles(t)
+
+	packages, err := Complexity(files, &ComplexityConfig{Top: 2})
+	assert.NoError(err)
+	if assert.Len(packages, 2) {
+		assert.Equal("a", packages[0].Name)
+		assert.Equal(3, packages[0].Count)
+		if assert.Len(packages[0].Functions, 2) {
+			assert.Equal("three", packages[0].Functions[0].Name)
+			assert.Equal("two", packages[0].Functions[1].Name)
+		}
+
+		assert.Equal("b", packages[1].Name)
+		assert.Equal("flat", packages[1].Functions[0].Name)
+	}
+
+	packages, err = Complexity(files, &ComplexityConfig{SortBy: MetricCognitive})
+	assert.NoError(err)
+	if assert.Len(packages, 2) {
+		assert.Len(packages[0].Functions, 3)
+		assert.Equal("nested", packages[1].Functions[0].Name)
+		assert.Equal(3, packages[1].Functions[0].Cognitive)
+	}
+}
+
+func TestWriteComplexity(t *testing.T) {
+	assert := testAssert.New(t)
+	packages, err := Complexity(writeComplexityFiles(t), &ComplexityConfig{Top: 1})
+	assert.NoError(err)
+
+	var buf bytes.Buffer
+	assert.NoError(WriteComplexity(&buf, FormatText, packages))
+	assert.Contains(buf.String(), "(package a, 3 functions)")
+	assert.Regexp(`3 +2 +three +.*a\.go:10:1`, buf.String())
+
+	buf.Reset()
+	assert.NoError(WriteComplexity(&buf, FormatJSON, packages))
+	var doc jsonComplexityReport
+	assert.NoError(json.Unmarshal(buf.Bytes(), &doc))
+	if assert.Len(doc.Packages, 2) {
+		assert.Equal(3, doc.Packages[0].Count)
+		assert.Equal("three", doc.Packages[0].Functions[0].Name)
+		assert.Equal(10, doc.Packages[0].Functions[0].Line)
+	}
+
+	assert.Error(WriteComplexity(&buf, FormatSARIF, packages))
+
+	_, err = ParseMetric("halstead")
+	assert.Error(err)
+}
@@ -0,0 +1,271 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lint
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/LokiWager/analysis-demo/pkg/ast"
+)
+
+type (
+	// ChangedLines holds the added or modified lines of the new version of every changed file,
+	// keyed by the absolute path of the file
+	ChangedLines map[string]map[int]bool
+)
+
+// hunkHeader matches the "@@ -start,count +start,count @@" line of a hunk
+var hunkHeader = regexp.MustCompile(`^@@ -\d+(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// GitDiff runs git diff against the base ref in the directory and returns the changed lines,
+// uncommitted changes and untracked files are included and renamed files are reported under their new path
+func GitDiff(dir, base string) (ChangedLines, error) {
+	root, err := GitRoot(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	// explicit prefixes, the diff.noprefix and diff.mnemonicPrefix settings change them
+	out, err := runGit(dir, "diff", "--no-color", "--no-ext-diff", "--unified=0", "--find-renames",
+		"--src-prefix=a/", "--dst-prefix=b/", base, "--")
+	if err != nil {
+		return nil, err
+	}
+
+	changed, err := ParseDiff(strings.NewReader(out), root)
+	if err != nil {
+		return nil, err
+	}
+
+	// untracked files are not in the diff, all their lines are new
+	untracked, err := runGit(root, "ls-files", "--others", "--exclude-standard", "-z")
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range strings.Split(untracked, "\x00") {
+		if name == "" {
+			continue
+		}
+		if err := changed.addFile(filepath.Join(root, filepath.FromSlash(name))); err != nil {
+			return nil, err
+		}
+	}
+
+	return changed, nil
+}
+
+// GitRoot returns the top-level directory of the git work tree containing dir
+func GitRoot(dir string) (string, error) {
+	root, err := runGit(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(root), nil
+}
+
+func runGit(dir string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s failed: %v: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.String(), nil
+}
+
+// ParseDiff parses a unified diff, the paths in the diff are relative to root
+func ParseDiff(r io.Reader, root string) (ChangedLines, error) {
+	root, err := realPath(root)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := ChangedLines{}
+	gitFormat := false
+	var lines map[int]bool
+	// line is the next line of the new file, oldLeft and newLeft the lines of the hunk not read yet
+	line, oldLeft, newLeft := 0, 0, 0
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		text := scanner.Text()
+
+		// within a hunk, a line like "+++ x" is an added line "++ x", not a file header
+		if oldLeft > 0 || newLeft > 0 {
+			switch {
+			case strings.HasPrefix(text, "+"):
+				if lines != nil {
+					lines[line] = true
+				}
+				line++
+				newLeft--
+			case strings.HasPrefix(text, "-"):
+				oldLeft--
+			case strings.HasPrefix(text, `\`):
+				// "\ No newline at end of file"
+			default:
+				// a context line, empty if its leading space was stripped
+				line++
+				oldLeft--
+				newLeft--
+			}
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(text, "diff --git "):
+			gitFormat = true
+			lines = nil
+		case strings.HasPrefix(text, "--- "):
+			// the old file, only the new one matters
+		case strings.HasPrefix(text, "+++ "):
+			path, err := diffPath(strings.TrimPrefix(text, "+++ "), gitFormat)
+			if err != nil {
+				return nil, err
+			}
+			if path == "" {
+				// the file is deleted
+				lines = nil
+				continue
+			}
+
+			path = filepath.Join(root, filepath.FromSlash(path))
+			if changed[path] == nil {
+				changed[path] = map[int]bool{}
+			}
+			lines = changed[path]
+		case strings.HasPrefix(text, "@@"):
+			match := hunkHeader.FindStringSubmatch(text)
+			if match == nil {
+				return nil, fmt.Errorf("invalid hunk header: %s", text)
+			}
+			oldLeft, line, newLeft = hunkNumber(match[1]), hunkNumber(match[2]), hunkNumber(match[3])
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return changed, nil
+}
+
+// hunkNumber returns the number of a hunk header, a missing count is 1
+func hunkNumber(number string) int {
+	if number == "" {
+		return 1
+	}
+
+	// the header pattern only matches digits
+	n, _ := strconv.Atoi(number)
+	return n
+}
+
+// addFile marks all the lines of the file as changed
+func (c ChangedLines) addFile(file string) error {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	path, err := realPath(file)
+	if err != nil {
+		return err
+	}
+
+	n := bytes.Count(content, []byte("\n"))
+	if len(content) > 0 && !bytes.HasSuffix(content, []byte("\n")) {
+		n++
+	}
+	lines := make(map[int]bool, n)
+	for line := 1; line <= n; line++ {
+		lines[line] = true
+	}
+	c[path] = lines
+
+	return nil
+}
+
+// diffPath extracts the path of the new file from a "+++" header, an empty path for a deleted file
+func diffPath(header string, gitFormat bool) (string, error) {
+	// non-git diffs may append a timestamp after a tab
+	header, _, _ = strings.Cut(header, "\t")
+	if header == "/dev/null" {
+		return "", nil
+	}
+
+	if strings.HasPrefix(header, `"`) {
+		unquoted, err := strconv.Unquote(header)
+		if err != nil {
+			return "", fmt.Errorf("invalid path in diff: %s", header)
+		}
+		header = unquoted
+	}
+
+	if gitFormat {
+		header = strings.TrimPrefix(header, "b/")
+	}
+
+	return header, nil
+}
+
+// Filter returns the findings on changed lines
+func (c ChangedLines) Filter(findings []ast.Finding) ([]ast.Finding, error) {
+	kept := make([]ast.Finding, 0)
+	for _, finding := range findings {
+		path, err := realPath(finding.Position.Filename)
+		if err != nil {
+			return nil, err
+		}
+
+		if c[path][finding.Position.Line] {
+			kept = append(kept, finding)
+		}
+	}
+
+	return kept, nil
+}
+
+// realPath returns the absolute path with symbolic links resolved, so paths reported by git match,
+// the absolute path is returned if the path does not exist
+func realPath(path string) (string, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved, nil
+	}
+
+	return path, nil
+}
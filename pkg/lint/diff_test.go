@@ -0,0 +1,163 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lint
+
+import (
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	testAssert "github.com/stretchr/testify/assert"
+
+	"github.com/LokiWager/analysis-demo/pkg/ast"
+)
+
+const testDiff = `diff --git a/pkg/a.go b/pkg/a.go
+index 1111111..2222222 100644
+--- a/pkg/a.go
++++ b/pkg/a.go
+@@ -3,0 +4,2 @@ func A() {
++	x := 1
++	_ = x
+@@ -10 +12 @@ func B() {
+-	return
++	return nil
+diff --git a/old.go b/new.go
+similarity index 90%
+rename from old.go
+rename to new.go
+--- a/old.go
++++ b/new.go
+@@ -5,3 +5,3 @@ package main
+ func C() {
+-	old()
++	renamed()
+ }
+diff --git a/moved.go b/moved2.go
+similarity index 100%
+rename from moved.go
+rename to moved2.go
+diff --git a/deleted.go b/deleted.go
+deleted file mode 100644
+--- a/deleted.go
++++ /dev/null
+@@ -1,2 +0,0 @@
+-package main
+-
+`
+
+func TestParseDiff(t *testing.T) {
+	assert := testAssert.New(t)
+	root := t.TempDir()
+	changed, err := ParseDiff(strings.NewReader(testDiff), root)
+	assert.NoError(err)
+
+	root, _ = realPath(root)
+	assert.Equal(ChangedLines{
+		filepath.Join(root, "pkg", "a.go"): {4: true, 5: true, 12: true},
+		filepath.Join(root, "new.go"):      {6: true},
+	}, changed)
+}
+
+func TestParseDiff_Plain(t *testing.T) {
+	assert := testAssert.New(t)
+	root := t.TempDir()
+	diff := "--- a.go\t2024-01-01 00:00:00\n+++ a.go\t2024-01-02 00:00:00\n@@ -1,2 +1,3 @@\n package main\n+\n+func A() {}\n"
+	changed, err := ParseDiff(strings.NewReader(diff), root)
+	assert.NoError(err)
+
+	root, _ = realPath(root)
+	assert.Equal(ChangedLines{filepath.Join(root, "a.go"): {2: true, 3: true}}, changed)
+
+	// lines of a hunk looking like headers are content
+	diff = "+++ a.go\n@@ -1,2 +1,3 @@\n package main\n--- removed\n+++ added\n+++ b.go\n"
+	changed, err = ParseDiff(strings.NewReader(diff), root)
+	assert.NoError(err)
+	assert.Equal(ChangedLines{filepath.Join(root, "a.go"): {2: true, 3: true}}, changed)
+
+	_, err = ParseDiff(strings.NewReader("+++ b.go\n@@ invalid @@\n"), root)
+	assert.Error(err)
+}
+
+func TestChangedLines_Filter(t *testing.T) {
+	assert := testAssert.New(t)
+	root := t.TempDir()
+	changed, err := ParseDiff(strings.NewReader(testDiff), root)
+	assert.NoError(err)
+
+	findings := []ast.Finding{
+		{Rule: "a", Position: token.Position{Filename: filepath.Join(root, "pkg", "a.go"), Line: 4}},
+		{Rule: "b", Position: token.Position{Filename: filepath.Join(root, "pkg", "a.go"), Line: 6}},
+		{Rule: "c", Position: token.Position{Filename: filepath.Join(root, "new.go"), Line: 6}},
+		{Rule: "d", Position: token.Position{Filename: filepath.Join(root, "other.go"), Line: 4}},
+	}
+	kept, err := changed.Filter(findings)
+	assert.NoError(err)
+	assert.Len(kept, 2)
+	assert.Equal("a", kept[0].Rule)
+	assert.Equal("c", kept[1].Rule)
+}
+
+func TestGitDiff(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+
+	assert := testAssert.New(t)
+	dir := t.TempDir()
+	git := func(args ...string) {
+		if _, err := runGit(dir, args...); err != nil {
+			t.Fatalf("%v", err)
+		}
+	}
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s failed: %v", name, err)
+		}
+	}
+
+	git("init", "-q")
+	git("config", "user.email", "test@example.com")
+	git("config", "user.name", "test")
+	git("config", "diff.noprefix", "true")
+	write("a.go", "package main\n\nfunc A() {\n}\n")
+	git("add", "-A")
+	git("commit", "-q", "-m", "init")
+
+	git("mv", "a.go", "b.go")
+	write("b.go", "package main\n\nfunc A() {\n\tprintln()\n}\n")
+	write("c.go", "package main\n\nfunc C() {}")
+	write(".gitignore", "*.log\n")
+	write("debug.log", "ignored\n")
+
+	changed, err := GitDiff(dir, "HEAD")
+	assert.NoError(err)
+
+	dir, _ = realPath(dir)
+	assert.Equal(ChangedLines{
+		filepath.Join(dir, "b.go"):       {4: true},
+		filepath.Join(dir, "c.go"):       {1: true, 2: true, 3: true},
+		filepath.Join(dir, ".gitignore"): {1: true},
+	}, changed)
+
+	_, err = GitDiff(dir, "unknown-ref")
+	assert.Error(err)
+}
@@ -0,0 +1,199 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/LokiWager/analysis-demo/pkg/ast"
+)
+
+type (
+	// PackageDocCoverage is the doc comment coverage of the exported declarations of a package
+	PackageDocCoverage struct {
+		// Dir is the directory of the package
+		Dir string
+
+		// Name is the name of the package
+		Name string
+
+		// Exported is the number of exported declarations of the package
+		Exported int
+
+		// Documented is the number of exported declarations with a doc comment
+		Documented int
+
+		// Undocumented are the exported declarations without a doc comment in source order
+		Undocumented []ast.ExportedDecl
+	}
+
+	jsonDocCoverageReport struct {
+		Min      float64                  `json:"min"`
+		Packages []jsonPackageDocCoverage `json:"packages"`
+	}
+
+	jsonPackageDocCoverage struct {
+		Dir          string             `json:"dir"`
+		Name         string             `json:"name"`
+		Exported     int                `json:"exported"`
+		Documented   int                `json:"documented"`
+		Coverage     float64            `json:"coverage"`
+		BelowMin     bool               `json:"belowMin"`
+		Undocumented []jsonExportedDecl `json:"undocumented"`
+	}
+
+	jsonExportedDecl struct {
+		Name   string `json:"name"`
+		Kind   string `json:"kind"`
+		File   string `json:"file"`
+		Line   int    `json:"line"`
+		Column int    `json:"column"`
+	}
+)
+
+// Coverage returns the percentage of documented exported declarations, 100 for a package exporting nothing
+func (p PackageDocCoverage) Coverage() float64 {
+	if p.Exported == 0 {
+		return 100
+	}
+
+	return 100 * float64(p.Documented) / float64(p.Exported)
+}
+
+// DocCoverage computes the doc comment coverage of the exported declarations of the files per package,
+// test files are not part of the API and are skipped, packages are ordered by directory and name
+func DocCoverage(files []string) ([]PackageDocCoverage, error) {
+	byPackage := make(map[packageKey]*PackageDocCoverage)
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+
+		e, err := ast.NewEngine(file, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, decl := range e.ExportedDecls() {
+			key := packageKey{dir: filepath.Dir(file), name: decl.Package}
+			pkg, exists := byPackage[key]
+			if !exists {
+				pkg = &PackageDocCoverage{Dir: key.dir, Name: key.name}
+				byPackage[key] = pkg
+			}
+
+			pkg.Exported++
+			if decl.Documented {
+				pkg.Documented++
+			} else {
+				pkg.Undocumented = append(pkg.Undocumented, decl)
+			}
+		}
+	}
+
+	packages := make([]PackageDocCoverage, 0, len(byPackage))
+	for _, pkg := range byPackage {
+		packages = append(packages, *pkg)
+	}
+
+	sortPackages(packages, func(i int) packageKey {
+		return packageKey{packages[i].Dir, packages[i].Name}
+	})
+
+	return packages, nil
+}
+
+// BelowMinCoverage returns the packages whose coverage is below the minimum percentage
+func BelowMinCoverage(packages []PackageDocCoverage, minCoverage float64) []PackageDocCoverage {
+	var below []PackageDocCoverage
+	for _, pkg := range packages {
+		if pkg.Coverage() < minCoverage {
+			below = append(below, pkg)
+		}
+	}
+
+	return below
+}
+
+// WriteDocCoverage writes the doc coverage report to w as text or JSON, the packages below the minimum are marked
+func WriteDocCoverage(w io.Writer, format Format, packages []PackageDocCoverage, minCoverage float64) error {
+	switch format {
+	case FormatText:
+		return writeDocCoverageText(w, packages, minCoverage)
+	case FormatJSON:
+		return writeDocCoverageJSON(w, packages, minCoverage)
+	}
+
+	return fmt.Errorf("format %s not supported by the doc coverage report, use %s or %s", format, FormatText, FormatJSON)
+}
+
+func writeDocCoverageText(w io.Writer, packages []PackageDocCoverage, minCoverage float64) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "COVERAGE\tDOCUMENTED\tPACKAGE\tDIR\t")
+	exported, documented := 0, 0
+	for _, pkg := range packages {
+		exported += pkg.Exported
+		documented += pkg.Documented
+
+		mark := ""
+		if pkg.Coverage() < minCoverage {
+			mark = fmt.Sprintf("below %.1f%%", minCoverage)
+		}
+		fmt.Fprintf(tw, "%.1f%%\t%d/%d\t%s\t%s\t%s\n", pkg.Coverage(), pkg.Documented, pkg.Exported, pkg.Name, pkg.Dir, mark)
+	}
+
+	total := PackageDocCoverage{Exported: exported, Documented: documented}
+	fmt.Fprintf(tw, "%.1f%%\t%d/%d\ttotal\t\t\n", total.Coverage(), documented, exported)
+
+	return tw.Flush()
+}
+
+func writeDocCoverageJSON(w io.Writer, packages []PackageDocCoverage, minCoverage float64) error {
+	doc := jsonDocCoverageReport{Min: minCoverage, Packages: make([]jsonPackageDocCoverage, 0, len(packages))}
+	for _, pkg := range packages {
+		undocumented := make([]jsonExportedDecl, 0, len(pkg.Undocumented))
+		for _, decl := range pkg.Undocumented {
+			undocumented = append(undocumented, jsonExportedDecl{
+				Name:   decl.Name,
+				Kind:   decl.Kind,
+				File:   decl.Position.Filename,
+				Line:   decl.Position.Line,
+				Column: decl.Position.Column,
+			})
+		}
+
+		doc.Packages = append(doc.Packages, jsonPackageDocCoverage{
+			Dir:          pkg.Dir,
+			Name:         pkg.Name,
+			Exported:     pkg.Exported,
+			Documented:   pkg.Documented,
+			Coverage:     pkg.Coverage(),
+			BelowMin:     pkg.Coverage() < minCoverage,
+			Undocumented: undocumented,
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
@@ -0,0 +1,103 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	testAssert "github.com/stretchr/testify/assert"
+)
+
+func writeDocCoverageFiles(t *testing.T) []string {
+	dir := t.TempDir()
+	sources := map[string]string{
+		"a/a.go":      "package a\n\n// Run runs\nfunc Run() {}\n\nfunc Stop() {}\n\ntype (\n\t// Service serves\n\tService struct{}\n)\n\nfunc (s *Service) GetUsage() {}\n\nfunc helper() {}\n",
+		"a/a_test.go": "package a\n\nfunc TestRun() {}\n",
+		"b/b.go":      "package b\n\n// Version is the version\nconst Version = \"1\"\n\nfunc internal() {}\n",
+	}
+
+	var files []string
+	for _, name := range []string{"a/a.go", "a/a_test.go", "b/b.go"} {
+		file := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			t.Fatalf("create %s failed: %v", file, err)
+		}
+		if err := os.WriteFile(file, []byte(sources[name]), 0o644); err != nil {
+			t.Fatalf("write %s failed: %v", file, err)
+		}
+		files = append(files, file)
+	}
+
+	return files
+}
+
+func TestDocCoverage(t *testing.T) {
+	assert := testAssert.New(t)
+	packages, err := DocCoverage(writeDocCoverageFiles(t))
+	assert.NoError(err)
+	if assert.Len(packages, 2) {
+		assert.Equal("a", packages[0].Name)
+		assert.Equal(4, packages[0].Exported)
+		assert.Equal(2, packages[0].Documented)
+		assert.Equal(50.0, packages[0].Coverage())
+		if assert.Len(packages[0].Undocumented, 2) {
+			assert.Equal("Stop", packages[0].Undocumented[0].Name)
+			assert.Equal("Service.GetUsage", packages[0].Undocumented[1].Name)
+			assert.Equal("method", packages[0].Undocumented[1].Kind)
+		}
+
+		assert.Equal("b", packages[1].Name)
+		assert.Equal(100.0, packages[1].Coverage())
+	}
+
+	below := BelowMinCoverage(packages, 80)
+	if assert.Len(below, 1) {
+		assert.Equal("a", below[0].Name)
+	}
+	assert.Empty(BelowMinCoverage(packages, 0))
+}
+
+func TestWriteDocCoverage(t *testing.T) {
+	assert := testAssert.New(t)
+	packages, err := DocCoverage(writeDocCoverageFiles(t))
+	assert.NoError(err)
+
+	var buf bytes.Buffer
+	assert.NoError(WriteDocCoverage(&buf, FormatText, packages, 80))
+	assert.Regexp(`50\.0% +2/4 +a +.*below 80\.0%`, buf.String())
+	assert.Regexp(`100\.0% +1/1 +b`, buf.String())
+	assert.Regexp(`60\.0% +3/5 +total`, buf.String())
+
+	buf.Reset()
+	assert.NoError(WriteDocCoverage(&buf, FormatJSON, packages, 80))
+	var doc jsonDocCoverageReport
+	assert.NoError(json.Unmarshal(buf.Bytes(), &doc))
+	if assert.Len(doc.Packages, 2) {
+		assert.True(doc.Packages[0].BelowMin)
+		assert.False(doc.Packages[1].BelowMin)
+		if assert.Len(doc.Packages[0].Undocumented, 2) {
+			assert.Equal(6, doc.Packages[0].Undocumented[0].Line)
+		}
+	}
+
+	assert.Error(WriteDocCoverage(&buf, FormatSARIF, packages, 0))
+}
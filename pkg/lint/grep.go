@@ -0,0 +1,97 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lint
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/LokiWager/analysis-demo/pkg/ast"
+)
+
+type (
+	// GrepConfig is the configuration of a structural search
+	GrepConfig struct {
+		// Pattern is the pattern searched, see ast.Pattern
+		Pattern string
+
+		// Types are the type constraints of the metavariables keyed by name without $
+		Types map[string]string
+
+		// Load resolves the packages to type-check when the pattern has type constraints
+		Load *LoadConfig
+
+		// Workers is the number of files searched in parallel, the number of CPUs if not positive
+		Workers int
+	}
+)
+
+// grepPattern is the name of the pattern of the search in the parameters of the pattern rule
+const grepPattern = "grep"
+
+// Grep searches the files for the code matching the pattern, the message of every finding is the matched code,
+// the packages are type-checked first if the pattern has type constraints
+func Grep(files []string, grepConfig *GrepConfig) (*Report, error) {
+	params := ast.Params{grepPattern + ".match": grepConfig.Pattern}
+	for name, constraint := range grepConfig.Types {
+		params[grepPattern+".types."+name] = constraint
+	}
+
+	runConfig := &RunConfig{
+		Enable:  []string{ast.PatternRuleName},
+		Params:  map[string]ast.Params{ast.PatternRuleName: params},
+		Workers: grepConfig.Workers,
+	}
+	requires, err := runConfig.RequiresTypes()
+	if err != nil {
+		return nil, err
+	}
+	if requires {
+		if runConfig.Types, err = LoadTypes(grepConfig.Load); err != nil {
+			return nil, fmt.Errorf("type-check packages failed: %w", err)
+		}
+	}
+
+	report, err := Run(files, runConfig)
+	if err != nil {
+		return nil, err
+	}
+	// syntax and type errors keep their message
+	for i := range report.Findings {
+		if report.Findings[i].Rule == ast.PatternRuleName {
+			report.Findings[i].Message = report.Findings[i].Subject
+		}
+	}
+
+	return report, nil
+}
+
+// ParseTypeConstraints parses the type constraints of metavariables given as name=type
+func ParseTypeConstraints(values []string) (map[string]string, error) {
+	constraints := make(map[string]string, len(values))
+	for _, value := range values {
+		name, constraint, found := strings.Cut(value, "=")
+		name = strings.TrimPrefix(strings.TrimSpace(name), "$")
+		if !found || name == "" || strings.TrimSpace(constraint) == "" {
+			return nil, fmt.Errorf("invalid type constraint %s, want metavariable=type", value)
+		}
+		constraints[name] = strings.TrimSpace(constraint)
+	}
+
+	return constraints, nil
+}
@@ -0,0 +1,89 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	testAssert "github.com/stretchr/testify/assert"
+)
+
+func TestGrep(t *testing.T) {
+	assert := testAssert.New(t)
+	dir := t.TempDir()
+	assert.NoError(os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/grep\n\ngo 1.22\n"), 0o644))
+	file := filepath.Join(dir, "main.go")
+	assert.NoError(os.WriteFile(file, []byte(`package main
+
+import "sync"
+
+type process struct {
+	State string
+}
+
+func main() {
+	var processes sync.Map
+	value, _ := processes.Load(1)
+	value.(*process).State = "running"
+
+	var states map[int]string
+	_, _ = states[1], value
+}
+`), 0o644))
+
+	report, err := Grep([]string{file}, &GrepConfig{Pattern: "$x.State = $_"})
+	assert.NoError(err)
+	if assert.Len(report.Findings, 1) {
+		assert.Equal(`value.(*process).State = "running"`, report.Findings[0].Message)
+		assert.Equal(12, report.Findings[0].Position.Line)
+	}
+
+	// the type constraints type-check the packages
+	report, err = Grep([]string{file}, &GrepConfig{
+		Pattern: "$m.Load($_)",
+		Types:   map[string]string{"m": "sync.Map"},
+		Load:    &LoadConfig{Dir: dir},
+	})
+	assert.NoError(err)
+	if assert.Len(report.Findings, 1) {
+		assert.Equal("processes.Load(1)", report.Findings[0].Message)
+	}
+
+	report, err = Grep([]string{file}, &GrepConfig{
+		Pattern: "$m[$_]",
+		Types:   map[string]string{"m": "sync.Map"},
+		Load:    &LoadConfig{Dir: dir},
+	})
+	assert.NoError(err)
+	assert.Empty(report.Findings)
+
+	_, err = Grep([]string{file}, &GrepConfig{Pattern: "$x +"})
+	assert.Error(err)
+}
+
+func TestParseTypeConstraints(t *testing.T) {
+	assert := testAssert.New(t)
+	constraints, err := ParseTypeConstraints([]string{"$m=*sync.Map", "x = int"})
+	assert.NoError(err)
+	assert.Equal(map[string]string{"m": "*sync.Map", "x": "int"}, constraints)
+
+	_, err = ParseTypeConstraints([]string{"m"})
+	assert.Error(err)
+}
@@ -0,0 +1,317 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lint
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+
+	lintast "github.com/LokiWager/analysis-demo/pkg/ast"
+)
+
+type (
+	// LoadConfig is the configuration to resolve the go files to lint
+	LoadConfig struct {
+		// Dir is the directory the patterns are resolved in, the current directory if empty
+		Dir string
+
+		// Patterns are the go package patterns, e.g. ./... or import paths, ./... if empty
+		Patterns []string
+
+		// Tags are the build tags to honor
+		Tags []string
+
+		// GOOS is the target operating system, the host one if empty
+		GOOS string
+
+		// GOARCH is the target architecture, the host one if empty
+		GOARCH string
+
+		// Tests includes the _test.go files if true
+		Tests bool
+
+		// Ignored includes the go files excluded by build constraints, e.g. the files of the other
+		// operating systems, for the analyses comparing files without compiling them
+		Ignored bool
+	}
+
+	// TypedFile is a file parsed and type-checked with its package
+	TypedFile struct {
+		// FileSet is the file set of the package
+		FileSet *token.FileSet
+
+		// File is the syntax tree of the file
+		File *ast.File
+
+		// Package are the syntax trees of the files of the package, File among them
+		Package []*ast.File
+
+		// Info is the type information of the package, partial if the package has errors
+		Info *types.Info
+
+		// Errors are the syntax and type errors of the package in the file as findings
+		Errors []lintast.Finding
+
+		// Digest is the digest of the files of the package, the findings of the rules requiring types depend on all of them
+		Digest string
+	}
+)
+
+// skippedDirs are the directories whose files are never linted
+var skippedDirs = []string{"vendor", "testdata"}
+
+// LoadFiles resolves the package patterns and returns the go files to lint in alphabetical order,
+// files in vendor or testdata directories and generated files are skipped
+func LoadFiles(config *LoadConfig) ([]string, error) {
+	pkgs, err := packages.Load(config.packagesConfig(packages.NeedName|packages.NeedFiles), config.patterns()...)
+	if err != nil {
+		return nil, fmt.Errorf("load packages failed: %w", err)
+	}
+
+	root, err := filepath.Abs(config.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	seen := make(map[string]bool)
+	files := make([]string, 0)
+	for _, pkg := range pkgs {
+		for _, pkgErr := range pkg.Errors {
+			errs = append(errs, pkgErr)
+		}
+
+		// the test main package is synthesized by the go tool
+		if strings.HasSuffix(pkg.ID, ".test") {
+			continue
+		}
+
+		for _, file := range config.goFiles(pkg) {
+			if seen[file] {
+				continue
+			}
+			seen[file] = true
+
+			if inSkippedDir(root, file) {
+				continue
+			}
+
+			generated, err := isGenerated(file)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if generated {
+				continue
+			}
+
+			files = append(files, file)
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// LoadTypes type-checks the packages of the patterns and returns their files keyed by absolute file name,
+// the files of packages with errors come with the partial type information and the errors as findings
+func LoadTypes(config *LoadConfig) (map[string]*TypedFile, error) {
+	mode := packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles | packages.NeedImports |
+		packages.NeedSyntax | packages.NeedTypes | packages.NeedTypesInfo | packages.NeedDeps
+	pkgs, err := packages.Load(config.packagesConfig(mode), config.patterns()...)
+	if err != nil {
+		return nil, fmt.Errorf("load packages failed: %w", err)
+	}
+
+	files := make(map[string]*TypedFile)
+	for _, pkg := range pkgs {
+		if pkg.TypesInfo == nil || strings.HasSuffix(pkg.ID, ".test") || len(pkg.Syntax) != len(pkg.CompiledGoFiles) ||
+			len(pkg.Syntax) == 0 {
+			continue
+		}
+
+		digest, err := packageDigest(pkg.CompiledGoFiles)
+		if err != nil {
+			return nil, err
+		}
+		errs := packageErrors(pkg)
+		for i, file := range pkg.Syntax {
+			files[pkg.CompiledGoFiles[i]] = &TypedFile{
+				FileSet: pkg.Fset,
+				File:    file,
+				Package: pkg.Syntax,
+				Info:    pkg.TypesInfo,
+				Errors:  errs[pkg.CompiledGoFiles[i]],
+				Digest:  digest,
+			}
+		}
+	}
+
+	return files, nil
+}
+
+// packageErrors returns the syntax and type errors of the package as findings keyed by file name,
+// the errors outside of the files of the package, e.g. of its imports, are reported on its first file
+func packageErrors(pkg *packages.Package) map[string][]lintast.Finding {
+	known := make(map[string]bool, len(pkg.CompiledGoFiles))
+	for _, file := range pkg.CompiledGoFiles {
+		known[file] = true
+	}
+
+	errs := make(map[string][]lintast.Finding)
+	for _, pkgErr := range pkg.Errors {
+		for _, finding := range lintast.ErrorFindings(pkgErr) {
+			if !known[finding.Position.Filename] {
+				finding.Position = token.Position{Filename: pkg.CompiledGoFiles[0]}
+				finding.End = finding.Position
+			}
+			errs[finding.Position.Filename] = append(errs[finding.Position.Filename], finding)
+		}
+	}
+
+	return errs
+}
+
+// packagesConfig returns the configuration loading the packages with the mode
+func (c *LoadConfig) packagesConfig(mode packages.LoadMode) *packages.Config {
+	env := os.Environ()
+	if c.GOOS != "" {
+		env = append(env, "GOOS="+c.GOOS)
+	}
+	if c.GOARCH != "" {
+		env = append(env, "GOARCH="+c.GOARCH)
+	}
+
+	var buildFlags []string
+	if len(c.Tags) > 0 {
+		buildFlags = append(buildFlags, "-tags="+strings.Join(c.Tags, ","))
+	}
+
+	return &packages.Config{
+		Mode:       mode,
+		Dir:        c.Dir,
+		Env:        env,
+		BuildFlags: buildFlags,
+		Tests:      c.Tests,
+	}
+}
+
+// goFiles returns the go files of the package, with the files excluded by build constraints if Ignored
+func (c *LoadConfig) goFiles(pkg *packages.Package) []string {
+	if !c.Ignored {
+		return pkg.GoFiles
+	}
+
+	files := append([]string(nil), pkg.GoFiles...)
+	for _, file := range pkg.IgnoredFiles {
+		if strings.HasSuffix(file, ".go") && (c.Tests || !strings.HasSuffix(file, "_test.go")) {
+			files = append(files, file)
+		}
+	}
+
+	return files
+}
+
+// patterns returns the package patterns, ./... if none
+func (c *LoadConfig) patterns() []string {
+	if len(c.Patterns) == 0 {
+		return []string{"./..."}
+	}
+
+	return c.Patterns
+}
+
+// ModuleRoot returns the directory of the go.mod file of dir, found in dir or its parents,
+// dir itself outside of a module
+func ModuleRoot(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for current := dir; ; {
+		if info, err := os.Stat(filepath.Join(current, "go.mod")); err == nil && !info.IsDir() {
+			return current, nil
+		}
+
+		parent := filepath.Dir(current)
+		if parent == current {
+			return dir, nil
+		}
+		current = parent
+	}
+}
+
+// packageDigest returns the digest of the content of the files
+func packageDigest(files []string) (string, error) {
+	hash := sha256.New()
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return "", err
+		}
+		hash.Write([]byte(file))
+		hash.Write(content)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// inSkippedDir reports whether the file is in a skipped directory below the root
+func inSkippedDir(root, file string) bool {
+	rel, err := filepath.Rel(root, file)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		rel = file
+	}
+
+	for _, part := range strings.Split(filepath.ToSlash(filepath.Dir(rel)), "/") {
+		for _, dir := range skippedDirs {
+			if part == dir {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// isGenerated reports whether the file has the "Code generated ... DO NOT EDIT." comment
+func isGenerated(file string) (bool, error) {
+	f, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.PackageClauseOnly|parser.ParseComments)
+	if err != nil {
+		return false, err
+	}
+
+	return ast.IsGenerated(f), nil
+}
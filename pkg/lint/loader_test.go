@@ -0,0 +1,109 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	testAssert "github.com/stretchr/testify/assert"
+)
+
+func relFiles(t *testing.T, dir string, files []string) []string {
+	rel := make([]string, 0, len(files))
+	for _, file := range files {
+		r, err := filepath.Rel(dir, file)
+		if err != nil {
+			t.Fatalf("rel %s failed: %v", file, err)
+		}
+		rel = append(rel, filepath.ToSlash(r))
+	}
+	return rel
+}
+
+func TestLoadFiles(t *testing.T) {
+	dir, err := filepath.Abs("testdata/mod")
+	if err != nil {
+		t.Fatalf("abs failed: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		config   *LoadConfig
+		expected []string
+	}{
+		{
+			name:     "Recursive pattern",
+			config:   &LoadConfig{Dir: dir, GOOS: "linux"},
+			expected: []string{"a.go", "sub/b.go", "sub/c_linux.go"},
+		},
+		{
+			name:     "Import path pattern",
+			config:   &LoadConfig{Dir: dir, Patterns: []string{"example.com/mod/sub"}, GOOS: "windows"},
+			expected: []string{"sub/b.go"},
+		},
+		{
+			name:     "Build tags",
+			config:   &LoadConfig{Dir: dir, Patterns: []string{"./sub"}, Tags: []string{"extra"}, GOOS: "linux"},
+			expected: []string{"sub/b.go", "sub/c_linux.go", "sub/d_extra.go"},
+		},
+		{
+			name:     "Test files",
+			config:   &LoadConfig{Dir: dir, Patterns: []string{"."}, Tests: true},
+			expected: []string{"a.go", "a_test.go"},
+		},
+		{
+			name:     "Ignored files",
+			config:   &LoadConfig{Dir: dir, Patterns: []string{"./sub"}, GOOS: "windows", Ignored: true},
+			expected: []string{"sub/b.go", "sub/c_linux.go", "sub/d_extra.go"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := testAssert.New(t)
+			files, err := LoadFiles(test.config)
+			assert.NoError(err)
+			assert.Equal(test.expected, relFiles(t, dir, files))
+		})
+	}
+
+	t.Run("Unknown package", func(t *testing.T) {
+		assert := testAssert.New(t)
+		_, err := LoadFiles(&LoadConfig{Dir: dir, Patterns: []string{"./missing"}})
+		assert.Error(err)
+	})
+}
+
+func TestModuleRoot(t *testing.T) {
+	assert := testAssert.New(t)
+	dir := t.TempDir()
+	sub := filepath.Join(dir, "pkg", "sub")
+	assert.NoError(os.MkdirAll(sub, 0o755))
+
+	// outside of a module the directory is its own root
+	root, err := ModuleRoot(sub)
+	assert.NoError(err)
+	assert.Equal(sub, root)
+
+	assert.NoError(os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/m\n"), 0o644))
+	root, err = ModuleRoot(sub)
+	assert.NoError(err)
+	assert.Equal(dir, root)
+}
@@ -0,0 +1,231 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/LokiWager/analysis-demo/pkg/ast"
+)
+
+type (
+	// Format is the output format of a report
+	Format string
+
+	// Report is the result of a lint run
+	Report struct {
+		// Files are the analyzed files
+		Files []string
+
+		// Rules are the rules that were run
+		Rules []ast.Rule
+
+		// Findings are the findings of all files sorted by position
+		Findings []ast.Finding
+	}
+
+	// formatter writes the report in a format
+	formatter func(w io.Writer, report *Report) error
+
+	jsonReport struct {
+		Findings []jsonFinding `json:"findings"`
+	}
+
+	jsonFinding struct {
+		Rule      string       `json:"rule"`
+		Severity  ast.Severity `json:"severity"`
+		Message   string       `json:"message"`
+		Subject   string       `json:"subject,omitempty"`
+		File      string       `json:"file"`
+		Line      int          `json:"line"`
+		Column    int          `json:"column"`
+		EndLine   int          `json:"endLine"`
+		EndColumn int          `json:"endColumn"`
+	}
+)
+
+const (
+	// FormatText writes one "file:line:column: message (rule)" line per finding
+	FormatText Format = "text"
+
+	// FormatJSON writes the findings as a JSON document
+	FormatJSON Format = "json"
+
+	// FormatSARIF writes the findings as a SARIF 2.1.0 log
+	FormatSARIF Format = "sarif"
+
+	// FormatCheckstyle writes the findings as a Checkstyle XML report
+	FormatCheckstyle Format = "checkstyle"
+
+	// FormatJUnit writes the findings as a JUnit XML report, one test suite per file
+	FormatJUnit Format = "junit"
+)
+
+var formatters = map[Format]formatter{
+	FormatText:       writeText,
+	FormatJSON:       writeJSON,
+	FormatSARIF:      writeSARIF,
+	FormatCheckstyle: writeCheckstyle,
+	FormatJUnit:      writeJUnit,
+}
+
+// Formats returns the supported output formats in alphabetical order
+func Formats() []string {
+	formats := make([]string, 0, len(formatters))
+	for format := range formatters {
+		formats = append(formats, string(format))
+	}
+	sort.Strings(formats)
+
+	return formats
+}
+
+// Failed reports whether the report has findings at least as severe as the threshold,
+// an empty threshold never fails
+func (r *Report) Failed(threshold ast.Severity) bool {
+	if threshold == "" {
+		return false
+	}
+
+	for _, finding := range r.Findings {
+		if finding.Severity.AtLeast(threshold) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ParseFailOn parses the severity threshold failing a run, "none" never fails
+func ParseFailOn(name string) (ast.Severity, error) {
+	if name == "none" {
+		return "", nil
+	}
+
+	return ast.ParseSeverity(name)
+}
+
+// ParseSeverities parses the "rule=severity" severity overrides
+func ParseSeverities(values []string) (map[string]ast.Severity, error) {
+	severities := make(map[string]ast.Severity, len(values))
+	for _, value := range values {
+		name, level, found := strings.Cut(value, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid severity override %s, want rule=severity", value)
+		}
+		if _, exists := ast.RuleRegistry[name]; !exists {
+			return nil, fmt.Errorf("rule %s not found", name)
+		}
+
+		severity, err := ast.ParseSeverity(level)
+		if err != nil {
+			return nil, err
+		}
+		severities[name] = severity
+	}
+
+	return severities, nil
+}
+
+// ParseParams parses the "rule.param=value" rule parameters
+func ParseParams(values []string) (map[string]ast.Params, error) {
+	params := make(map[string]ast.Params)
+	for _, value := range values {
+		key, param, found := strings.Cut(value, "=")
+		name, key, dotted := strings.Cut(key, ".")
+		if !found || !dotted || key == "" {
+			return nil, fmt.Errorf("invalid rule parameter %s, want rule.param=value", value)
+		}
+		if _, exists := ast.RuleRegistry[name]; !exists {
+			return nil, fmt.Errorf("rule %s not found", name)
+		}
+
+		if params[name] == nil {
+			params[name] = ast.Params{}
+		}
+		params[name][key] = param
+	}
+
+	return params, nil
+}
+
+// ParseFormat parses the name of a supported output format
+func ParseFormat(name string) (Format, error) {
+	format := Format(name)
+	if _, exists := formatters[format]; !exists {
+		return "", fmt.Errorf("format %s not supported, use one of %v", name, Formats())
+	}
+
+	return format, nil
+}
+
+// ParseSummaryFormat parses the name of an output format of the summary reports,
+// the complexity, doc coverage and clone reports, which are written as text or JSON only
+func ParseSummaryFormat(name string) (Format, error) {
+	format := Format(name)
+	if format != FormatText && format != FormatJSON {
+		return "", fmt.Errorf("format %s not supported by the report, use %s or %s", name, FormatText, FormatJSON)
+	}
+
+	return format, nil
+}
+
+// WriteReport writes the report to w in the given format
+func WriteReport(w io.Writer, format Format, report *Report) error {
+	write, exists := formatters[format]
+	if !exists {
+		return fmt.Errorf("format %s not supported", format)
+	}
+
+	return write(w, report)
+}
+
+func writeText(w io.Writer, report *Report) error {
+	for _, finding := range report.Findings {
+		if _, err := fmt.Fprintln(w, finding); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeJSON(w io.Writer, report *Report) error {
+	doc := jsonReport{Findings: make([]jsonFinding, 0, len(report.Findings))}
+	for _, finding := range report.Findings {
+		doc.Findings = append(doc.Findings, jsonFinding{
+			Rule:      finding.Rule,
+			Severity:  finding.Severity,
+			Message:   finding.Message,
+			Subject:   finding.Subject,
+			File:      finding.Position.Filename,
+			Line:      finding.Position.Line,
+			Column:    finding.Position.Column,
+			EndLine:   finding.End.Line,
+			EndColumn: finding.End.Column,
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
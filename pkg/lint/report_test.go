@@ -0,0 +1,263 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"go/token"
+	"strings"
+	"testing"
+
+	testAssert "github.com/stretchr/testify/assert"
+
+	"github.com/LokiWager/analysis-demo/pkg/ast"
+)
+
+func newTestReport() *Report {
+	return &Report{
+		Files: []string{"a.go", "b.go"},
+		Rules: []ast.Rule{ast.NewIdentLengthRule(), ast.NewNestingRule()},
+		Findings: []ast.Finding{
+			{
+				Rule:     ast.NestingRuleName,
+				Severity: ast.SeverityError,
+				Message:  "found more than 4 level nested control flow",
+				Subject:  "if true",
+				Position: token.Position{Filename: "a.go", Line: 10, Column: 6},
+				End:      token.Position{Filename: "a.go", Line: 12, Column: 7},
+			},
+			{
+				Rule:     ast.IdentLengthRuleName,
+				Severity: ast.SeverityInfo,
+				Message:  "identifier idEqual13xxxx has length 13",
+				Subject:  "idEqual13xxxx",
+				Position: token.Position{Filename: "a.go", Line: 14, Column: 6},
+				End:      token.Position{Filename: "a.go", Line: 14, Column: 19},
+			},
+		},
+	}
+}
+
+func TestWriteReport_Text(t *testing.T) {
+	assert := testAssert.New(t)
+	var buf bytes.Buffer
+	assert.NoError(WriteReport(&buf, FormatText, newTestReport()))
+	assert.Equal("a.go:10:6: found more than 4 level nested control flow (nesting-depth)\n"+
+		"a.go:14:6: identifier idEqual13xxxx has length 13 (ident-length)\n", buf.String())
+}
+
+func TestWriteReport_JSON(t *testing.T) {
+	assert := testAssert.New(t)
+	var buf bytes.Buffer
+	assert.NoError(WriteReport(&buf, FormatJSON, newTestReport()))
+
+	var doc jsonReport
+	assert.NoError(json.Unmarshal(buf.Bytes(), &doc))
+	assert.Len(doc.Findings, 2)
+	assert.Equal(jsonFinding{
+		Rule:      ast.NestingRuleName,
+		Severity:  ast.SeverityError,
+		Message:   "found more than 4 level nested control flow",
+		Subject:   "if true",
+		File:      "a.go",
+		Line:      10,
+		Column:    6,
+		EndLine:   12,
+		EndColumn: 7,
+	}, doc.Findings[0])
+}
+
+func TestWriteReport_SARIF(t *testing.T) {
+	assert := testAssert.New(t)
+	var buf bytes.Buffer
+	assert.NoError(WriteReport(&buf, FormatSARIF, newTestReport()))
+
+	var doc sarifLog
+	assert.NoError(json.Unmarshal(buf.Bytes(), &doc))
+	assert.Equal("2.1.0", doc.Version)
+	assert.Len(doc.Runs, 1)
+	assert.Len(doc.Runs[0].Tool.Driver.Rules, 2)
+	assert.Equal(ast.IdentLengthRuleName, doc.Runs[0].Tool.Driver.Rules[0].ID)
+
+	results := doc.Runs[0].Results
+	assert.Len(results, 2)
+	assert.Equal(ast.NestingRuleName, results[0].RuleID)
+	assert.Equal(1, results[0].RuleIndex)
+	assert.Equal("error", results[0].Level)
+	assert.Equal("note", results[1].Level)
+	assert.Equal("a.go", results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.Equal(&sarifRegion{StartLine: 10, StartColumn: 6, EndLine: 12, EndColumn: 7}, results[0].Locations[0].PhysicalLocation.Region)
+}
+
+func TestWriteReport_Checkstyle(t *testing.T) {
+	assert := testAssert.New(t)
+	var buf bytes.Buffer
+	assert.NoError(WriteReport(&buf, FormatCheckstyle, newTestReport()))
+	assert.True(strings.HasPrefix(buf.String(), xml.Header))
+
+	var doc checkstyleReport
+	assert.NoError(xml.Unmarshal(buf.Bytes(), &doc))
+	assert.Len(doc.Files, 2)
+	assert.Equal("a.go", doc.Files[0].Name)
+	assert.Len(doc.Files[0].Errors, 2)
+	assert.Equal(checkstyleError{
+		Line:     14,
+		Column:   6,
+		Severity: "info",
+		Message:  "identifier idEqual13xxxx has length 13",
+		Source:   ast.IdentLengthRuleName,
+	}, doc.Files[0].Errors[1])
+	assert.Empty(doc.Files[1].Errors)
+}
+
+func TestWriteReport_JUnit(t *testing.T) {
+	assert := testAssert.New(t)
+	var buf bytes.Buffer
+	assert.NoError(WriteReport(&buf, FormatJUnit, newTestReport()))
+
+	var doc junitTestSuites
+	assert.NoError(xml.Unmarshal(buf.Bytes(), &doc))
+	assert.Equal(3, doc.Tests)
+	assert.Equal(2, doc.Failures)
+	assert.Len(doc.Suites, 2)
+	assert.Equal("nesting-depth:10:6", doc.Suites[0].Cases[0].Name)
+	assert.Equal("error", doc.Suites[0].Cases[0].Failure.Type)
+	assert.Equal(0, doc.Suites[1].Failures)
+	assert.Nil(doc.Suites[1].Cases[0].Failure)
+}
+
+func TestWriteReport_Unsupported(t *testing.T) {
+	assert := testAssert.New(t)
+	assert.Error(WriteReport(&bytes.Buffer{}, Format("yaml"), newTestReport()))
+}
+
+func TestParseFormat(t *testing.T) {
+	assert := testAssert.New(t)
+	format, err := ParseFormat("sarif")
+	assert.NoError(err)
+	assert.Equal(FormatSARIF, format)
+
+	_, err = ParseFormat("yaml")
+	assert.Error(err)
+
+	format, err = ParseSummaryFormat("json")
+	assert.NoError(err)
+	assert.Equal(FormatJSON, format)
+
+	_, err = ParseSummaryFormat("sarif")
+	assert.Error(err)
+}
+
+func TestReport_Failed(t *testing.T) {
+	report := newTestReport()
+	tests := []struct {
+		failOn   string
+		expected bool
+	}{
+		{"info", true},
+		{"warning", true},
+		{"error", true},
+		{"none", false},
+	}
+
+	for _, test := range tests {
+		threshold, err := ParseFailOn(test.failOn)
+		if err != nil {
+			t.Fatalf("parse fail-on %s failed: %v", test.failOn, err)
+		}
+		if report.Failed(threshold) != test.expected {
+			t.Errorf("Unexpected failed result for %s: want %v", test.failOn, test.expected)
+		}
+	}
+
+	report.Findings = report.Findings[1:]
+	threshold, _ := ParseFailOn("warning")
+	if report.Failed(threshold) {
+		t.Errorf("Unexpected failed result for info findings with warning threshold")
+	}
+}
+
+func TestParseSeverities(t *testing.T) {
+	assert := testAssert.New(t)
+	severities, err := ParseSeverities([]string{"ident-length=error", "nesting-depth=info"})
+	assert.NoError(err)
+	assert.Equal(map[string]ast.Severity{
+		ast.IdentLengthRuleName: ast.SeverityError,
+		ast.NestingRuleName:     ast.SeverityInfo,
+	}, severities)
+
+	_, err = ParseSeverities([]string{"ident-length"})
+	assert.Error(err)
+	_, err = ParseSeverities([]string{"unknown=error"})
+	assert.Error(err)
+	_, err = ParseSeverities([]string{"ident-length=fatal"})
+	assert.Error(err)
+}
+
+func TestParseParams(t *testing.T) {
+	assert := testAssert.New(t)
+	params, err := ParseParams([]string{"nesting-depth.max-depth=3"})
+	assert.NoError(err)
+	assert.Equal(map[string]ast.Params{
+		ast.NestingRuleName: {"max-depth": "3"},
+	}, params)
+
+	_, err = ParseParams([]string{"nesting-depth=3"})
+	assert.Error(err)
+	_, err = ParseParams([]string{"nesting-depth.max-depth"})
+	assert.Error(err)
+	_, err = ParseParams([]string{"unknown.max-depth=3"})
+	assert.Error(err)
+}
+
+func TestWriteReport_SARIFUnknownRule(t *testing.T) {
+	assert := testAssert.New(t)
+	report := newTestReport()
+	report.Findings = append(report.Findings, ast.Finding{
+		Rule:     ast.DirectiveRuleName,
+		Severity: ast.SeverityError,
+		Message:  "//lint:ignore needs a rule and a reason",
+		Position: token.Position{Filename: "b.go", Line: 3, Column: 1},
+	})
+
+	var buf bytes.Buffer
+	assert.NoError(WriteReport(&buf, FormatSARIF, report))
+
+	var doc sarifLog
+	assert.NoError(json.Unmarshal(buf.Bytes(), &doc))
+	assert.Len(doc.Runs[0].Tool.Driver.Rules, 3)
+	assert.Equal(ast.DirectiveRuleName, doc.Runs[0].Tool.Driver.Rules[2].ID)
+	assert.Equal(2, doc.Runs[0].Results[2].RuleIndex)
+}
+
+func TestWriteReport_SARIFFileFinding(t *testing.T) {
+	assert := testAssert.New(t)
+	report := &Report{Findings: []ast.Finding{{
+		Rule:     ast.LicenseHeaderRuleName,
+		Severity: ast.SeverityWarning,
+		Message:  "missing license header",
+		Position: token.Position{Filename: "b.go"},
+	}}}
+
+	var buf bytes.Buffer
+	assert.NoError(WriteReport(&buf, FormatSARIF, report))
+	assert.NotContains(buf.String(), "region")
+	assert.NotContains(buf.String(), "startLine")
+}
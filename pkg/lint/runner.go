@@ -0,0 +1,471 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lint
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/LokiWager/analysis-demo/pkg/ast"
+	"github.com/LokiWager/analysis-demo/pkg/config"
+)
+
+type (
+	// RunConfig is the configuration of a lint run
+	RunConfig struct {
+		// Enable is the list of rules to run, the default rules of the registry if empty
+		Enable []string `json:"enable"`
+
+		// Disable is the list of rules to skip
+		Disable []string `json:"disable"`
+
+		// Severities overrides the severities of the rules, keyed by rule name
+		Severities map[string]ast.Severity `json:"severities"`
+
+		// Params are the parameters of the rules, keyed by rule name
+		Params map[string]ast.Params `json:"params"`
+
+		// ReportUnusedSuppressions reports the suppression directives matching no finding
+		ReportUnusedSuppressions bool `json:"reportUnusedSuppressions"`
+
+		// Workers is the number of files analyzed in parallel, the number of CPUs if not positive
+		Workers int `json:"-"`
+
+		// Cache stores the findings of unchanged files, nil disables caching
+		Cache *Cache `json:"-"`
+
+		// Project is the project configuration, the rules of the run apply over its rules, nil for none
+		Project *config.Config `json:"-"`
+
+		// Types are the type-checked files keyed by absolute file name, the rules requiring types
+		// are skipped on the files missing from it
+		Types map[string]*TypedFile `json:"-"`
+	}
+
+	fileResult struct {
+		findings []ast.Finding
+		err      error
+	}
+)
+
+// NewRules creates the rules of the run with their parameters applied and their severities overridden
+func (c *RunConfig) NewRules() ([]ast.Rule, error) {
+	rules, err := ast.NewRules(c.Enable, c.Disable)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ast.ConfigureRules(rules, c.Params); err != nil {
+		return nil, err
+	}
+
+	return ast.OverrideSeverities(rules, c.Severities), nil
+}
+
+// Run lints the files with a bounded pool of workers,
+// the report lists the files and findings in the order of the files whatever the scheduling
+func Run(files []string, runConfig *RunConfig) (*Report, error) {
+	base := runConfig
+	if runConfig.Project != nil {
+		var err error
+		if base, err = runConfig.withProjectRules(runConfig.Project.Rules); err != nil {
+			return nil, err
+		}
+	}
+
+	rules, err := base.NewRules()
+	if err != nil {
+		return nil, err
+	}
+
+	workers := runConfig.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	results := make([]fileResult, len(files))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				findings, err := runConfig.lintFile(files[index])
+				results[index] = fileResult{findings: findings, err: err}
+			}
+		}()
+	}
+
+	for i := range files {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	report := &Report{Files: files, Rules: rules}
+	for _, result := range results {
+		if result.err != nil {
+			return nil, result.err
+		}
+		report.Findings = append(report.Findings, result.findings...)
+	}
+	ast.SortFindings(report.Findings)
+
+	return report, nil
+}
+
+// Validate creates the rules of the run and of every override of the project configuration,
+// so invalid rule names, severities or parameters are reported before any file is analyzed
+func (c *RunConfig) Validate() error {
+	_, err := c.allRules()
+	return err
+}
+
+// RequiresTypes reports whether a rule of the run or of an override of the project configuration requires types,
+// so the packages are only type-checked when needed
+func (c *RunConfig) RequiresTypes() (bool, error) {
+	rules, err := c.allRules()
+	if err != nil {
+		return false, err
+	}
+
+	for _, rule := range rules {
+		if ast.RequiresTypes(rule) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// allRules creates the rules of the run and of every override of the project configuration
+func (c *RunConfig) allRules() ([]ast.Rule, error) {
+	if c.Project == nil {
+		return c.NewRules()
+	}
+
+	projectRules := []config.Rules{c.Project.Rules}
+	for _, override := range c.Project.Overrides {
+		projectRules = append(projectRules, c.Project.Rules.Merge(override.Rules))
+	}
+
+	var all []ast.Rule
+	for _, rules := range projectRules {
+		merged, err := c.withProjectRules(rules)
+		var created []ast.Rule
+		if err == nil {
+			created, err = merged.NewRules()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", c.Project.Path, err)
+		}
+		all = append(all, created...)
+	}
+
+	return all, nil
+}
+
+// ForFile returns the configuration of the run for the file:
+// the rules of the run apply over the rules the project configuration has for the file
+func (c *RunConfig) ForFile(file string) (*RunConfig, error) {
+	if c.Project == nil {
+		return c, nil
+	}
+
+	return c.withProjectRules(c.Project.RulesFor(file))
+}
+
+// withProjectRules returns the configuration of the run applied over the rules of the project configuration
+func (c *RunConfig) withProjectRules(project config.Rules) (*RunConfig, error) {
+	merged := *c
+	merged.Project = nil
+
+	merged.Enable = project.Enable
+	if len(c.Enable) > 0 {
+		merged.Enable = c.Enable
+	}
+	merged.Disable = append(append([]string(nil), project.Disable...), c.Disable...)
+
+	merged.Severities = make(map[string]ast.Severity, len(project.Severity)+len(c.Severities))
+	for name, level := range project.Severity {
+		if _, exists := ast.RuleRegistry[name]; !exists {
+			return nil, fmt.Errorf("rule %s not found", name)
+		}
+		severity, err := ast.ParseSeverity(level)
+		if err != nil {
+			return nil, err
+		}
+		merged.Severities[name] = severity
+	}
+	for name, severity := range c.Severities {
+		merged.Severities[name] = severity
+	}
+
+	merged.Params = make(map[string]ast.Params, len(project.Params)+len(c.Params))
+	for _, params := range []map[string]ast.Params{toParams(project.Params), c.Params} {
+		for name, ruleParams := range params {
+			if merged.Params[name] == nil {
+				merged.Params[name] = ast.Params{}
+			}
+			for key, value := range ruleParams {
+				merged.Params[name][key] = value
+			}
+		}
+	}
+
+	return &merged, nil
+}
+
+func toParams(params map[string]map[string]any) map[string]ast.Params {
+	converted := make(map[string]ast.Params, len(params))
+	for name, ruleParams := range params {
+		converted[name] = ruleParams
+	}
+
+	return converted
+}
+
+// lintFile runs the rules on the file, or returns the cached findings if the file did not change
+func (c *RunConfig) lintFile(file string) ([]ast.Finding, error) {
+	// the project configuration may override the rules of the directory of the file
+	fileConfig, err := c.ForFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	// rules keep per-file state, create them for every file
+	rules, err := fileConfig.NewRules()
+	if err != nil {
+		return nil, err
+	}
+
+	typed, err := c.typedFile(file, rules)
+	if err != nil {
+		return nil, err
+	}
+
+	// a type-checked file comes with the files of its package
+	var siblings []string
+	if typed == nil && requiresPackage(rules) {
+		if siblings, err = packageFiles(file); err != nil {
+			return nil, err
+		}
+	}
+
+	var key string
+	if c.Cache != nil {
+		if key, err = fileConfig.cacheKey(file, rules, typed, siblings); err != nil {
+			return nil, err
+		}
+		if findings, ok := c.Cache.Get(key); ok {
+			logrus.Debugf("Using cached findings of %s", file)
+			return findings, nil
+		}
+	}
+
+	logrus.Infof("Analyzing %s", file)
+	var findings []ast.Finding
+	if typed != nil {
+		e := ast.NewEngineFromFile(typed.FileSet, typed.File)
+		e.SetTypesInfo(typed.Info)
+		e.SetPackageFiles(typed.Package)
+		e.SetReportUnusedSuppressions(c.ReportUnusedSuppressions)
+		findings = append(e.Run(rules...), typed.Errors...)
+		ast.SortFindings(findings)
+
+		// the packages are loaded with absolute file names
+		for i := range findings {
+			findings[i].Position.Filename = file
+			findings[i].End.Filename = file
+		}
+	} else {
+		e, err := ast.NewEngine(file, nil)
+		if err != nil {
+			return nil, err
+		}
+		if len(siblings) > 0 {
+			if err := e.LoadPackageFiles(siblings); err != nil {
+				return nil, err
+			}
+		}
+		e.SetReportUnusedSuppressions(c.ReportUnusedSuppressions)
+		findings = e.Run(rules...)
+	}
+
+	if c.Cache != nil {
+		if err := c.Cache.Put(key, findings); err != nil {
+			logrus.Warnf("Failed to cache findings of %s: %v", file, err)
+		}
+	}
+
+	return findings, nil
+}
+
+// cacheKey returns the cache key of the findings of the file, it depends on the content of the file,
+// the configuration and the files of the package the rules requiring types or the package depend on
+func (c *RunConfig) cacheKey(file string, rules []ast.Rule, typed *TypedFile, siblings []string) (string, error) {
+	configHash, err := c.hash(rules)
+	if err != nil {
+		return "", err
+	}
+	if typed != nil {
+		configHash += typed.Digest
+	} else if len(siblings) > 0 {
+		digest, err := packageDigest(siblings)
+		if err != nil {
+			return "", err
+		}
+		configHash += digest
+	}
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+
+	return c.Cache.Key(file, content, configHash), nil
+}
+
+// typedFile returns the type-checked file if a rule requires types, nil otherwise
+func (c *RunConfig) typedFile(file string, rules []ast.Rule) (*TypedFile, error) {
+	if len(c.Types) == 0 {
+		return nil, nil
+	}
+
+	for _, rule := range rules {
+		if !ast.RequiresTypes(rule) {
+			continue
+		}
+
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			return nil, err
+		}
+		return c.Types[abs], nil
+	}
+
+	return nil, nil
+}
+
+// requiresPackage reports whether a rule compares the files of the package
+func requiresPackage(rules []ast.Rule) bool {
+	for _, rule := range rules {
+		if ast.RequiresPackage(rule) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// packageFiles returns the go files of the package of the file in its directory, the _test.go files only
+// for a test file, files excluded by their build constraints or declaring another package, like an external
+// _test package, are left out. A file excluded itself is not built with the others and comes alone
+func packageFiles(file string) ([]string, error) {
+	dir := filepath.Dir(file)
+	match, err := build.Default.MatchFile(dir, filepath.Base(file))
+	if err != nil {
+		return nil, err
+	}
+	if !match {
+		return []string{file}, nil
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	name, err := packageName(file)
+	if err != nil {
+		return nil, err
+	}
+
+	test := strings.HasSuffix(file, "_test.go")
+	files := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if !test && strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		match, err := build.Default.MatchFile(dir, filepath.Base(path))
+		if err != nil {
+			return nil, err
+		}
+		if !match {
+			continue
+		}
+		pkg, err := packageName(path)
+		if err != nil {
+			return nil, err
+		}
+		if pkg == name {
+			files = append(files, path)
+		}
+	}
+
+	return files, nil
+}
+
+// packageName returns the package declared by the go file, parsing its package clause only
+func packageName(path string) (string, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.PackageClauseOnly)
+	if file == nil {
+		return "", fmt.Errorf("parse file %s failed: %w", path, err)
+	}
+
+	return file.Name.Name, nil
+}
+
+// ruleDigest identifies a resolved rule in the cache key, a rule changing its documentation
+// or default severity invalidates the findings cached by its previous version
+type ruleDigest struct {
+	Name     string       `json:"name"`
+	Doc      string       `json:"doc"`
+	Severity ast.Severity `json:"severity"`
+}
+
+// hash returns the hash of the configuration and the resolved rules, so changing either invalidates the cache
+func (c *RunConfig) hash(rules []ast.Rule) (string, error) {
+	digests := make([]ruleDigest, 0, len(rules))
+	for _, rule := range rules {
+		digests = append(digests, ruleDigest{Name: rule.Name(), Doc: rule.Doc(), Severity: rule.Severity()})
+	}
+
+	data, err := json.Marshal(struct {
+		Config *RunConfig   `json:"config"`
+		Rules  []ruleDigest `json:"rules"`
+	}{c, digests})
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
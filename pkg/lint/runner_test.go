@@ -0,0 +1,284 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lint
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	testAssert "github.com/stretchr/testify/assert"
+
+	"github.com/LokiWager/analysis-demo/pkg/ast"
+	"github.com/LokiWager/analysis-demo/pkg/config"
+)
+
+// licenseHeader is the header of the test files, so they have no license-header findings
+const licenseHeader = `/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+`
+
+func writeTestFiles(t *testing.T, dir string, n int) []string {
+	files := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		file := filepath.Join(dir, fmt.Sprintf("file%02d.go", i))
+		src := licenseHeader + fmt.Sprintf("package main\n\nfunc idEqual13xx%02d() {\n}\n", i)
+		if err := os.WriteFile(file, []byte(src), 0o644); err != nil {
+			t.Fatalf("write %s failed: %v", file, err)
+		}
+		files = append(files, file)
+	}
+	return files
+}
+
+func TestRun(t *testing.T) {
+	assert := testAssert.New(t)
+	files := writeTestFiles(t, t.TempDir(), 20)
+
+	sequential, err := Run(files, &RunConfig{Workers: 1})
+	assert.NoError(err)
+	assert.Len(sequential.Findings, 20)
+	assert.Equal(files, sequential.Files)
+
+	parallel, err := Run(files, &RunConfig{Workers: 8})
+	assert.NoError(err)
+	assert.Equal(sequential.Findings, parallel.Findings)
+	for i, finding := range parallel.Findings {
+		assert.Equal(files[i], finding.Position.Filename)
+	}
+
+	_, err = Run(files, &RunConfig{Enable: []string{"unknown"}})
+	assert.Error(err)
+}
+
+func TestRun_Cache(t *testing.T) {
+	assert := testAssert.New(t)
+	files := writeTestFiles(t, t.TempDir(), 2)
+	cache, err := NewCache(t.TempDir())
+	assert.NoError(err)
+
+	config := &RunConfig{Cache: cache}
+	report, err := Run(files, config)
+	assert.NoError(err)
+	assert.Len(report.Findings, 2)
+
+	// a cached entry is returned without analyzing the file again
+	rules, _ := config.NewRules()
+	siblings, err := packageFiles(files[0])
+	assert.NoError(err)
+	key, err := config.cacheKey(files[0], rules, nil, siblings)
+	assert.NoError(err)
+
+	// positions in the file set of the engine are not cached
+	cached, ok := cache.Get(key)
+	assert.True(ok)
+	assert.Len(cached, 1)
+	assert.Equal(report.Findings[0].Position, cached[0].Position)
+	assert.Equal(report.Findings[0].Message, cached[0].Message)
+
+	fake := []ast.Finding{{Rule: "fake", Message: "from cache"}}
+	assert.NoError(cache.Put(key, fake))
+	report, err = Run(files, config)
+	assert.NoError(err)
+	assert.Equal("fake", report.Findings[0].Rule)
+
+	// changing the content or the configuration misses the cache
+	assert.NoError(os.WriteFile(files[0], []byte(licenseHeader+"package main\n"), 0o644))
+	report, err = Run(files, config)
+	assert.NoError(err)
+	assert.Len(report.Findings, 1)
+	assert.Equal(ast.IdentLengthRuleName, report.Findings[0].Rule)
+
+	report, err = Run(files, &RunConfig{Cache: cache, Disable: []string{ast.IdentLengthRuleName}})
+	assert.NoError(err)
+	assert.Empty(report.Findings)
+}
+
+// revisedRule is a rule whose documentation changed in a later version
+type revisedRule struct {
+	ast.Rule
+}
+
+func (r revisedRule) Doc() string {
+	return r.Rule.Doc() + ", revised"
+}
+
+func TestRunConfig_Hash(t *testing.T) {
+	assert := testAssert.New(t)
+	config := &RunConfig{}
+	rule := ast.NewIdentLengthRule()
+
+	hash, err := config.hash([]ast.Rule{rule})
+	assert.NoError(err)
+	same, err := config.hash([]ast.Rule{ast.NewIdentLengthRule()})
+	assert.NoError(err)
+	assert.Equal(hash, same)
+
+	// a revised rule misses the findings cached by its previous version
+	revised, err := config.hash([]ast.Rule{revisedRule{rule}})
+	assert.NoError(err)
+	assert.NotEqual(hash, revised)
+}
+
+func TestRun_Project(t *testing.T) {
+	assert := testAssert.New(t)
+	root := t.TempDir()
+	assert.NoError(os.MkdirAll(filepath.Join(root, "legacy"), 0o755))
+	files := append(writeTestFiles(t, root, 1), writeTestFiles(t, filepath.Join(root, "legacy"), 1)...)
+
+	configFile := filepath.Join(root, config.FileName)
+	assert.NoError(os.WriteFile(configFile, []byte(`
+rules:
+  enable: [ident-length, nesting-depth]
+  severity:
+    ident-length: error
+overrides:
+  - path: legacy
+    rules:
+      disable: [ident-length]
+`), 0o644))
+	project, err := config.Load(configFile)
+	assert.NoError(err)
+
+	runConfig := &RunConfig{Project: project}
+	assert.NoError(runConfig.Validate())
+	report, err := Run(files, runConfig)
+	assert.NoError(err)
+	if assert.Len(report.Findings, 1) {
+		assert.Equal(files[0], report.Findings[0].Position.Filename)
+		assert.Equal(ast.SeverityError, report.Findings[0].Severity)
+	}
+	assert.Len(report.Rules, 2)
+
+	// the flags of the run apply over the project configuration
+	runConfig.Severities = map[string]ast.Severity{ast.IdentLengthRuleName: ast.SeverityInfo}
+	report, err = Run(files, runConfig)
+	assert.NoError(err)
+	if assert.Len(report.Findings, 1) {
+		assert.Equal(ast.SeverityInfo, report.Findings[0].Severity)
+	}
+
+	project.Overrides[0].Rules.Params = map[string]map[string]any{"nesting-depth": {"max-level": 3}}
+	assert.Error(runConfig.Validate())
+}
+
+func TestRun_Types(t *testing.T) {
+	assert := testAssert.New(t)
+	dir := t.TempDir()
+	assert.NoError(os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/typed\n\ngo 1.22\n"), 0o644))
+	file := filepath.Join(dir, "main.go")
+	assert.NoError(os.WriteFile(file, []byte("package main\n\nimport \"os\"\n\nfunc main() {\n\tos.Remove(\"lock\")\n}\n"), 0o644))
+
+	runConfig := &RunConfig{Enable: []string{ast.UncheckedErrorRuleName}}
+	requires, err := runConfig.RequiresTypes()
+	assert.NoError(err)
+	assert.True(requires)
+
+	// the rules requiring types are skipped without them
+	report, err := Run([]string{file}, runConfig)
+	assert.NoError(err)
+	assert.Empty(report.Findings)
+
+	runConfig.Types, err = LoadTypes(&LoadConfig{Dir: dir})
+	assert.NoError(err)
+	report, err = Run([]string{file}, runConfig)
+	assert.NoError(err)
+	if assert.Len(report.Findings, 1) {
+		assert.Equal("error result of os.Remove is not checked", report.Findings[0].Message)
+		assert.Equal(file, report.Findings[0].Position.Filename)
+		assert.Equal(6, report.Findings[0].Position.Line)
+	}
+
+	requires, err = (&RunConfig{Enable: []string{ast.IdentLengthRuleName}}).RequiresTypes()
+	assert.NoError(err)
+	assert.False(requires)
+
+	// type errors are findings, the rules requiring types still run on the partial type information
+	assert.NoError(os.WriteFile(file, []byte("package main\n\nimport \"os\"\n\nfunc main() {\n\tos.Remove(\"lock\")\n}\n\nfunc G() int { return \"s\" }\n"), 0o644))
+	runConfig.Types, err = LoadTypes(&LoadConfig{Dir: dir})
+	assert.NoError(err)
+	report, err = Run([]string{file}, runConfig)
+	assert.NoError(err)
+	if assert.Len(report.Findings, 2) {
+		assert.Equal(ast.UncheckedErrorRuleName, report.Findings[0].Rule)
+		assert.Equal(ast.TypeErrorRuleName, report.Findings[1].Rule)
+		assert.Equal(file, report.Findings[1].Position.Filename)
+		assert.Equal(9, report.Findings[1].Position.Line)
+	}
+}
+
+func TestRun_Package(t *testing.T) {
+	assert := testAssert.New(t)
+	dir := t.TempDir()
+	write := func(name, src string) string {
+		file := filepath.Join(dir, name)
+		assert.NoError(os.WriteFile(file, []byte(licenseHeader+src), 0o644))
+		return file
+	}
+	first := write("a.go", "package main\n\ntype task struct{}\n\nfunc (t *task) Run() {}\n\nfunc (t *task) Stop() {}\n")
+	second := write("b.go", "package main\n\nfunc (tk *task) Wait() {}\n")
+	write("c_test.go", "package main\n\nfunc (tk *task) Check() {}\n")
+	write("d_test.go", "package main_test\n")
+	ignored := write("e.go", "//go:build ignore\n\npackage main\n\nfunc (tk *task) Skip() {}\n\nfunc (tk *task) Pause() {}\n")
+
+	cache, err := NewCache(t.TempDir())
+	assert.NoError(err)
+	runConfig := &RunConfig{Enable: []string{ast.ReceiverRuleName}, Cache: cache}
+
+	// the methods of the other files of the package are compared, the test files and ignored files are not
+	report, err := Run([]string{second}, runConfig)
+	assert.NoError(err)
+	if assert.Len(report.Findings, 1) {
+		assert.Equal("receiver name tk of method task.Wait differs from t used by the other methods of task",
+			report.Findings[0].Message)
+		assert.Equal(second, report.Findings[0].Position.Filename)
+	}
+
+	// files excluded by their build constraints or declaring another package are not part of the package
+	siblings, err := packageFiles(second)
+	assert.NoError(err)
+	assert.Equal([]string{first, second}, siblings)
+	siblings, err = packageFiles(filepath.Join(dir, "d_test.go"))
+	assert.NoError(err)
+	assert.Equal([]string{filepath.Join(dir, "d_test.go")}, siblings)
+	siblings, err = packageFiles(ignored)
+	assert.NoError(err)
+	assert.Equal([]string{ignored}, siblings)
+
+	// changing another file of the package misses the cache
+	write("a.go", "package main\n\ntype task struct{}\n\nfunc (tk *task) Run() {}\n")
+	report, err = Run([]string{first, second}, runConfig)
+	assert.NoError(err)
+	assert.Empty(report.Findings)
+}
@@ -0,0 +1,183 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lint
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+
+	"github.com/LokiWager/analysis-demo/pkg/ast"
+)
+
+type (
+	sarifLog struct {
+		Version string     `json:"version"`
+		Schema  string     `json:"$schema"`
+		Runs    []sarifRun `json:"runs"`
+	}
+
+	sarifRun struct {
+		Tool    sarifTool     `json:"tool"`
+		Results []sarifResult `json:"results"`
+	}
+
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+
+	sarifDriver struct {
+		Name           string      `json:"name"`
+		InformationURI string      `json:"informationUri"`
+		Rules          []sarifRule `json:"rules"`
+	}
+
+	sarifRule struct {
+		ID                   string             `json:"id"`
+		ShortDescription     sarifMessage       `json:"shortDescription"`
+		DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+	}
+
+	sarifConfiguration struct {
+		Level string `json:"level"`
+	}
+
+	sarifResult struct {
+		RuleID    string          `json:"ruleId"`
+		RuleIndex int             `json:"ruleIndex"`
+		Level     string          `json:"level"`
+		Message   sarifMessage    `json:"message"`
+		Locations []sarifLocation `json:"locations"`
+	}
+
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+
+	sarifLocation struct {
+		PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	}
+
+	sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+		Region           *sarifRegion          `json:"region,omitempty"`
+	}
+
+	sarifArtifactLocation struct {
+		URI string `json:"uri"`
+	}
+
+	sarifRegion struct {
+		StartLine   int `json:"startLine"`
+		StartColumn int `json:"startColumn,omitempty"`
+		EndLine     int `json:"endLine,omitempty"`
+		EndColumn   int `json:"endColumn,omitempty"`
+	}
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+
+	// toolName is the name of the tool in the reports
+	toolName = "analysis"
+	toolURI  = "https://github.com/LokiWager/analysis-demo"
+)
+
+// sarifLevel maps the severity to the SARIF result level
+func sarifLevel(severity ast.Severity) string {
+	switch severity {
+	case ast.SeverityError:
+		return "error"
+	case ast.SeverityInfo:
+		return "note"
+	default:
+		return "warning"
+	}
+}
+
+func writeSARIF(w io.Writer, report *Report) error {
+	ruleIndex := make(map[string]int, len(report.Rules))
+	rules := make([]sarifRule, 0, len(report.Rules))
+	for i, rule := range report.Rules {
+		ruleIndex[rule.Name()] = i
+		rules = append(rules, sarifRule{
+			ID:                   rule.Name(),
+			ShortDescription:     sarifMessage{Text: rule.Doc()},
+			DefaultConfiguration: sarifConfiguration{Level: sarifLevel(rule.Severity())},
+		})
+	}
+
+	results := make([]sarifResult, 0, len(report.Findings))
+	for _, finding := range report.Findings {
+		// findings of the engine itself, e.g. malformed directives, have no rule in the report
+		if _, exists := ruleIndex[finding.Rule]; !exists {
+			ruleIndex[finding.Rule] = len(rules)
+			rules = append(rules, sarifRule{
+				ID:                   finding.Rule,
+				ShortDescription:     sarifMessage{Text: finding.Rule},
+				DefaultConfiguration: sarifConfiguration{Level: sarifLevel(finding.Severity)},
+			})
+		}
+
+		results = append(results, sarifResult{
+			RuleID:    finding.Rule,
+			RuleIndex: ruleIndex[finding.Rule],
+			Level:     sarifLevel(finding.Severity),
+			Message:   sarifMessage{Text: finding.Message},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(finding.Position.Filename)},
+					Region:           sarifRegionOf(finding),
+				},
+			}},
+		})
+	}
+
+	doc := sarifLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           toolName,
+				InformationURI: toolURI,
+				Rules:          rules,
+			}},
+			Results: results,
+		}},
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
+
+// sarifRegionOf returns the region of the finding, nil for a finding on the whole file,
+// SARIF lines and columns start at 1
+func sarifRegionOf(finding ast.Finding) *sarifRegion {
+	if finding.Position.Line <= 0 {
+		return nil
+	}
+
+	return &sarifRegion{
+		StartLine:   finding.Position.Line,
+		StartColumn: finding.Position.Column,
+		EndLine:     finding.End.Line,
+		EndColumn:   finding.End.Column,
+	}
+}
@@ -0,0 +1,164 @@
+/*
+ * Copyright (c) 2024, LokiWager
+ * All rights reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lint
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	"github.com/LokiWager/analysis-demo/pkg/ast"
+)
+
+type (
+	checkstyleReport struct {
+		XMLName xml.Name         `xml:"checkstyle"`
+		Version string           `xml:"version,attr"`
+		Files   []checkstyleFile `xml:"file"`
+	}
+
+	checkstyleFile struct {
+		Name   string            `xml:"name,attr"`
+		Errors []checkstyleError `xml:"error"`
+	}
+
+	checkstyleError struct {
+		Line     int    `xml:"line,attr"`
+		Column   int    `xml:"column,attr"`
+		Severity string `xml:"severity,attr"`
+		Message  string `xml:"message,attr"`
+		Source   string `xml:"source,attr"`
+	}
+
+	junitTestSuites struct {
+		XMLName  xml.Name         `xml:"testsuites"`
+		Name     string           `xml:"name,attr"`
+		Tests    int              `xml:"tests,attr"`
+		Failures int              `xml:"failures,attr"`
+		Suites   []junitTestSuite `xml:"testsuite"`
+	}
+
+	junitTestSuite struct {
+		Name     string          `xml:"name,attr"`
+		Tests    int             `xml:"tests,attr"`
+		Failures int             `xml:"failures,attr"`
+		Cases    []junitTestCase `xml:"testcase"`
+	}
+
+	junitTestCase struct {
+		Name      string        `xml:"name,attr"`
+		ClassName string        `xml:"classname,attr"`
+		Failure   *junitFailure `xml:"failure,omitempty"`
+	}
+
+	junitFailure struct {
+		Message string `xml:"message,attr"`
+		Type    string `xml:"type,attr"`
+		Text    string `xml:",chardata"`
+	}
+)
+
+const checkstyleVersion = "5.0"
+
+func writeXML(w io.Writer, doc any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// groupByFile groups the findings by file, keeping the order of the files in the report,
+// files with findings that are not in the report files are appended
+func groupByFile(report *Report) ([]string, map[string][]ast.Finding) {
+	files := append([]string(nil), report.Files...)
+	known := make(map[string]bool, len(files))
+	for _, file := range files {
+		known[file] = true
+	}
+
+	byFile := make(map[string][]ast.Finding)
+	for _, finding := range report.Findings {
+		file := finding.Position.Filename
+		if !known[file] {
+			known[file] = true
+			files = append(files, file)
+		}
+		byFile[file] = append(byFile[file], finding)
+	}
+
+	return files, byFile
+}
+
+func writeCheckstyle(w io.Writer, report *Report) error {
+	files, byFile := groupByFile(report)
+	doc := checkstyleReport{Version: checkstyleVersion}
+	for _, file := range files {
+		entry := checkstyleFile{Name: file}
+		for _, finding := range byFile[file] {
+			entry.Errors = append(entry.Errors, checkstyleError{
+				Line:     finding.Position.Line,
+				Column:   finding.Position.Column,
+				Severity: string(finding.Severity),
+				Message:  finding.Message,
+				Source:   finding.Rule,
+			})
+		}
+		doc.Files = append(doc.Files, entry)
+	}
+
+	return writeXML(w, doc)
+}
+
+func writeJUnit(w io.Writer, report *Report) error {
+	files, byFile := groupByFile(report)
+	doc := junitTestSuites{Name: toolName}
+	for _, file := range files {
+		suite := junitTestSuite{Name: file}
+		for _, finding := range byFile[file] {
+			suite.Cases = append(suite.Cases, junitTestCase{
+				Name:      fmt.Sprintf("%s:%d:%d", finding.Rule, finding.Position.Line, finding.Position.Column),
+				ClassName: file,
+				Failure: &junitFailure{
+					Message: finding.Message,
+					Type:    string(finding.Severity),
+					Text:    finding.String(),
+				},
+			})
+		}
+		suite.Failures = len(suite.Cases)
+		// a clean file is reported as a single passing test case
+		if len(suite.Cases) == 0 {
+			suite.Cases = append(suite.Cases, junitTestCase{Name: toolName, ClassName: file})
+		}
+		suite.Tests = len(suite.Cases)
+
+		doc.Suites = append(doc.Suites, suite)
+		doc.Tests += suite.Tests
+		doc.Failures += suite.Failures
+	}
+
+	return writeXML(w, doc)
+}
@@ -22,10 +22,13 @@ import (
 	"go/ast"
 	"go/token"
 	"log"
+	"path/filepath"
 	"strconv"
 	"strings"
 
 	"golang.org/x/tools/go/analysis"
+
+	"github.com/LokiWager/analysis-demo/pkg/config"
 )
 
 // TypeChecker is an interface that defines a method that the checker should implement.
@@ -41,7 +44,24 @@ var CheckerAnalyzer = &analysis.Analyzer{
 }
 
 func run(pass *analysis.Pass) (interface{}, error) {
+	// the files of a package share their directory, its project configuration is found once
+	projects := make(map[string]*config.Config)
 	for _, file := range pass.Files {
+		// the project configuration may exclude the file or disable checkers
+		fileName := pass.Fset.File(file.Pos()).Name()
+		dir := filepath.Dir(fileName)
+		project, exists := projects[dir]
+		if !exists {
+			var err error
+			if project, err = config.Find(dir); err != nil {
+				return nil, err
+			}
+			projects[dir] = project
+		}
+		if !project.Includes(fileName) {
+			continue
+		}
+
 		ast.Inspect(file, func(n ast.Node) bool {
 			decl, ok := n.(*ast.GenDecl)
 			if !ok || decl.Tok != token.VAR {
@@ -64,11 +84,10 @@ func run(pass *analysis.Pass) (interface{}, error) {
 
 					if decl.Doc != nil {
 						for _, comment := range decl.Doc.List {
-							// split // and trim spaces
-							comment.Text = strings.TrimPrefix(comment.Text, "//")
-							comment.Text = strings.TrimSpace(comment.Text)
-							checkerName, params, err := ParseComment(comment.Text)
-							if err == nil {
+							// split // and trim spaces, the AST is shared with the other analyzers and left unchanged
+							text := strings.TrimSpace(strings.TrimPrefix(comment.Text, "//"))
+							checkerName, params, err := ParseComment(text)
+							if err == nil && project.CheckerEnabled(checkerName) {
 								specValue := valueSpec.Values[i]
 								value, err := ExtractValue(specValue)
 
@@ -20,7 +20,10 @@ package typechecker
 import (
 	"testing"
 
+	"golang.org/x/tools/go/analysis"
 	"golang.org/x/tools/go/analysis/analysistest"
+
+	"github.com/LokiWager/analysis-demo/pkg/ast"
 )
 
 func TestCheckerAnalyzer(t *testing.T) {
@@ -28,6 +31,20 @@ func TestCheckerAnalyzer(t *testing.T) {
 	analysistest.Run(t, testData, CheckerAnalyzer, "example")
 }
 
+func TestCheckerAnalyzer_WithRules(t *testing.T) {
+	// the naming rule runs on the files the checker analyzed before it, its directives must be left intact
+	naming := ast.NewAnalyzer(ast.RuleRegistry[ast.NamingRuleName], nil)
+	combined := &analysis.Analyzer{
+		Name:     "checkernaming",
+		Doc:      "runs the naming rule after the checker on the same files",
+		Requires: []*analysis.Analyzer{CheckerAnalyzer},
+		Run:      naming.Run,
+	}
+
+	testData := analysistest.TestData()
+	analysistest.Run(t, testData, combined, "directives")
+}
+
 func TestChecker_Invalid(t *testing.T) {
 	err := RunChecker(50, "NonExistentChecker", nil)
 	if err == nil {